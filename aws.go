@@ -0,0 +1,23 @@
+package conflux
+
+type awsConfig struct {
+	Region          string `json:"aws_region" required:"true"`
+	AccessKeyID     string `json:"aws_access_key_id" required:"true"`
+	SecretAccessKey string `json:"aws_secret_access_key" required:"true"`
+	SessionToken    string `json:"aws_session_token"`
+	EndpointURL     string `json:"aws_endpoint_url"`
+	SSMPath         string `json:"aws_ssm_path"`
+	SecretID        string `json:"aws_secret_id"`
+}
+
+// Validate makes sure that there is at least one source to read from,
+// either an SSM parameter path or a Secrets Manager secret ID
+func (c *awsConfig) Validate(diagnostics map[string]string) bool {
+	if c.SSMPath != "" || c.SecretID != "" {
+		return true
+	}
+
+	diagnostics["aws_ssm_path"] = StatusMissing
+	diagnostics["aws_secret_id"] = StatusMissing
+	return false
+}
@@ -0,0 +1,67 @@
+package conflux
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/dannyvelas/conflux/internal/client"
+)
+
+var _ Reader = (*awsSecretReader)(nil)
+
+type awsSecretReader struct {
+	mapReader mapReader
+}
+
+// NewAWSSecretReader creates a new AWS reader using the provided config map to authenticate to AWS.
+// It reads SSM parameters under aws_ssm_path and/or the Secrets Manager secret aws_secret_id.
+// If both are set, Secrets Manager values override SSM values.
+func NewAWSSecretReader(configMap map[string]string) *awsSecretReader {
+	return &awsSecretReader{
+		mapReader: newMapReader(configMap),
+	}
+}
+
+func (r *awsSecretReader) Read() (ReadResult, error) {
+	config := awsConfig{}
+
+	diagnostics, err := Unmarshal(r.mapReader, &config)
+	if errors.Is(err, ErrInvalidFields) {
+		return NewDiagnosticReadResult(nil, diagnostics), ErrInvalidFields
+	} else if err != nil {
+		return nil, fmt.Errorf("error unmarshalling aws creds: %w", err)
+	}
+
+	awsClient := client.NewAWSClient(
+		config.Region,
+		config.AccessKeyID,
+		config.SecretAccessKey,
+		config.SessionToken,
+		config.EndpointURL,
+	)
+
+	source := config.EndpointURL
+	if source == "" {
+		source = config.Region
+	}
+
+	awsSecrets := make(map[string]string)
+	if config.SSMPath != "" {
+		parameters, err := awsClient.ReadParameters(config.SSMPath)
+		if err != nil {
+			return nil, &ReaderError{Reader: "aws", Source: source, Err: fmt.Errorf("error reading ssm parameters: %w", err)}
+		}
+		maps.Copy(awsSecrets, parameters)
+	}
+
+	if config.SecretID != "" {
+		secret, err := awsClient.ReadSecret(config.SecretID)
+		if err != nil {
+			return nil, &ReaderError{Reader: "aws", Source: source, Err: fmt.Errorf("error reading secrets manager secret: %w", err)}
+		}
+		maps.Copy(awsSecrets, secret)
+	}
+
+	return NewDiagnosticReadResult(awsSecrets, diagnostics), nil
+}
@@ -0,0 +1,78 @@
+package conflux
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAWSSecretReader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSSM.GetParametersByPath":
+			if body["Path"] != "/app/prod" || body["Recursive"] != true || body["WithDecryption"] != true {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if body["NextToken"] == nil {
+				w.Write([]byte(`{"Parameters":[{"Name":"/app/prod/db/host","Value":"db.internal"}],"NextToken":"page2"}`))
+				return
+			}
+			w.Write([]byte(`{"Parameters":[{"Name":"/app/prod/ssh_port","Value":"2222"}]}`))
+		case "secretsmanager.GetSecretValue":
+			w.Write([]byte(`{"SecretString":"{\"db_password\":\"hunter2\",\"ssh_port\":\"3333\"}"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cases := []struct {
+		name     string
+		env      []string
+		expected map[string]string
+	}{
+		{
+			name:     "ssm parameters by path",
+			env:      []string{"AWS_REGION=us-east-1", "AWS_ACCESS_KEY_ID=AKID", "AWS_SECRET_ACCESS_KEY=secret", "AWS_ENDPOINT_URL=" + server.URL, "AWS_SSM_PATH=/app/prod/"},
+			expected: map[string]string{"db.host": "db.internal", "ssh_port": "2222"},
+		},
+		{
+			name:     "secrets manager overrides ssm",
+			env:      []string{"AWS_REGION=us-east-1", "AWS_ACCESS_KEY_ID=AKID", "AWS_SECRET_ACCESS_KEY=secret", "AWS_ENDPOINT_URL=" + server.URL, "AWS_SSM_PATH=/app/prod/", "AWS_SECRET_ID=app/prod"},
+			expected: map[string]string{"db.host": "db.internal", "ssh_port": "3333", "db_password": "hunter2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewConfigMux(
+				WithEnvReader(WithEnviron(tc.env)),
+				WithAWSReader(),
+			)
+
+			configMap := make(map[string]string)
+			if _, err := Unmarshal(r, &configMap); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for k, v := range tc.expected {
+				if configMap[k] != v {
+					t.Errorf("expected %s to be %s, got %s", k, v, configMap[k])
+				}
+			}
+		})
+	}
+}
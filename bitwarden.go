@@ -1,17 +1,62 @@
 package conflux
 
+import "strings"
+
 type bitwardenConfig struct {
 	APIURL         string `json:"bitwarden_api_url"`
 	IdentityURL    string `json:"bitwarden_identity_url"`
-	AccessToken    string `json:"bitwarden_access_token" required:"true"`
+	AccessToken    string `json:"bitwarden_access_token"`
 	OrganizationID string `json:"bitwarden_organization_id" required:"true"`
 	StateFilePath  string `json:"bitwarden_state_file_path"`
+	ProjectID      string `json:"bitwarden_project_id"`
+	// BWSAccessToken and BWSServerURL are the names used by the official Bitwarden tooling
+	BWSAccessToken string `json:"bws_access_token"`
+	BWSServerURL   string `json:"bws_server_url"`
 }
 
 func newBitwardenConfig() bitwardenConfig {
 	return bitwardenConfig{
-		APIURL:        "https://api.bitwarden.com",
-		IdentityURL:   "https://identity.bitwarden.com",
 		StateFilePath: ".bw_state",
 	}
 }
+
+// Validate makes sure that there is an access token, with either of its names
+func (c *bitwardenConfig) Validate(diagnostics map[string]string) bool {
+	if c.AccessToken != "" || c.BWSAccessToken != "" {
+		diagnostics["bitwarden_access_token"] = StatusLoaded
+		return true
+	}
+
+	diagnostics["bitwarden_access_token"] = StatusMissing
+	return false
+}
+
+// resolve fills the access token and the urls from the names used by the official Bitwarden tooling,
+// and returns the keys that the credentials were read from.
+// The bitwarden_* keys take precedence. Like the bws CLI, the urls of a self-hosted server
+// are derived from bws_server_url, so https://vault.example.com has its API at https://vault.example.com/api
+func (c *bitwardenConfig) resolve() []string {
+	used := []string{"bitwarden_access_token"}
+	if c.AccessToken == "" {
+		c.AccessToken, used[0] = c.BWSAccessToken, "bws_access_token"
+	}
+
+	if serverURL := strings.TrimRight(c.BWSServerURL, "/"); serverURL != "" && (c.APIURL == "" || c.IdentityURL == "") {
+		used = append(used, "bws_server_url")
+		if c.APIURL == "" {
+			c.APIURL = serverURL + "/api"
+		}
+		if c.IdentityURL == "" {
+			c.IdentityURL = serverURL + "/identity"
+		}
+	}
+
+	if c.APIURL == "" {
+		c.APIURL = "https://api.bitwarden.com"
+	}
+	if c.IdentityURL == "" {
+		c.IdentityURL = "https://identity.bitwarden.com"
+	}
+
+	return used
+}
@@ -3,21 +3,35 @@ package conflux
 import (
 	"errors"
 	"fmt"
-
-	"github.com/dannyvelas/conflux/internal/client"
+	"strings"
+	"time"
 )
 
 var _ Reader = (*bitwardenSecretReader)(nil)
 
 type bitwardenSecretReader struct {
-	mapReader mapReader
+	mapReader    mapReader
+	projectIDs   []string
+	keyPrefix    string
+	target       any
+	mapper       NameMapper
+	fetchOptions fetchOptions
+	newStore     func(BitwardenCredentials) (SecretStore, error)
 }
 
 // NewBitwardenSecretReader creates a new Bitwarden secret reader using the provided config map to authenticate to Bitwarden.
-func NewBitwardenSecretReader(configMap map[string]string) *bitwardenSecretReader {
-	return &bitwardenSecretReader{
-		mapReader: newMapReader(configMap),
+func NewBitwardenSecretReader(configMap map[string]string, opts ...func(*bitwardenSecretReader)) *bitwardenSecretReader {
+	r := bitwardenSecretReader{
+		mapReader:    newMapReader(configMap),
+		fetchOptions: newFetchOptions(),
+		newStore:     newBitwardenStore,
+	}
+
+	for _, opt := range opts {
+		opt(&r)
 	}
+
+	return &r
 }
 
 func (r *bitwardenSecretReader) Read() (ReadResult, error) {
@@ -27,24 +41,128 @@ func (r *bitwardenSecretReader) Read() (ReadResult, error) {
 	if errors.Is(err, ErrInvalidFields) {
 		return NewDiagnosticReadResult(nil, diagnostics), ErrInvalidFields
 	} else if err != nil {
-		return nil, fmt.Errorf("error unmarshalling bitwarden creds: %v", err)
+		return nil, fmt.Errorf("error unmarshalling bitwarden creds: %w", err)
+	}
+	// the diagnostic of the reader tells which names its credentials were read from
+	diagnostics["bitwarden"] = fmt.Sprintf("Loaded: using %s", strings.Join(config.resolve(), ", "))
+
+	filter, err := r.secretFilter(config)
+	if err != nil {
+		return nil, fmt.Errorf("error building bitwarden secret filter: %w", err)
 	}
 
-	bitwardenClient, err := client.NewBitwardenClient(
-		config.APIURL,
-		config.IdentityURL,
-		config.AccessToken,
-		config.OrganizationID,
-		config.StateFilePath,
-	)
+	store, err := r.newStore(BitwardenCredentials{
+		APIURL:         config.APIURL,
+		IdentityURL:    config.IdentityURL,
+		AccessToken:    config.AccessToken,
+		OrganizationID: config.OrganizationID,
+		StateFilePath:  config.StateFilePath,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error initializing bitwarden client: %v", err)
+		return nil, &ReaderError{Reader: "bitwarden", Source: config.APIURL, Err: fmt.Errorf("error initializing bitwarden client: %w", err)}
 	}
 
-	bitwardenSecrets, err := bitwardenClient.ReadSecrets()
+	bitwardenSecrets, err := readSecretStore(store, filter, r.fetchOptions)
 	if err != nil {
-		return nil, fmt.Errorf("error reading bitwarden secrets: %v", err)
+		return nil, &ReaderError{Reader: "bitwarden", Source: config.APIURL, Err: fmt.Errorf("error reading bitwarden secrets: %w", err)}
 	}
 
 	return NewDiagnosticReadResult(bitwardenSecrets, diagnostics), nil
 }
+
+func (r *bitwardenSecretReader) secretFilter(config bitwardenConfig) (secretFilter, error) {
+	filter := secretFilter{
+		projectIDs: r.projectIDs,
+		keyPrefix:  r.keyPrefix,
+		mapper:     r.mapper,
+	}
+
+	for _, projectID := range strings.Split(config.ProjectID, ",") {
+		if projectID = strings.TrimSpace(projectID); projectID != "" {
+			filter.projectIDs = append(filter.projectIDs, projectID)
+		}
+	}
+
+	if r.target != nil {
+		fields, err := getMappedFields(r.target, r.mapper)
+		if err != nil {
+			return secretFilter{}, fmt.Errorf("error getting tagged fields: %w", err)
+		}
+		for _, field := range fields {
+			if isStringField(field.Type) {
+				filter.keys = append(filter.keys, field.tag)
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+func (r *bitwardenSecretReader) setFieldNameMapper(mapper NameMapper) {
+	r.mapper = mapper
+}
+
+// WithBitwardenProjectIDs makes the Bitwarden reader only read secrets that belong to one of the given projects
+// Project IDs can also be set with the bitwarden_project_id config key, as a comma-separated list.
+// The secrets are filtered only after they are fetched, since Bitwarden doesn't list the project of a secret,
+// so the values of secrets in other projects are still decrypted and fetched. Use WithBitwardenKeyPrefix or
+// WithBitwardenTarget to limit the secrets that are fetched
+func WithBitwardenProjectIDs(projectIDs ...string) func(*bitwardenSecretReader) {
+	return func(r *bitwardenSecretReader) {
+		r.projectIDs = append(r.projectIDs, projectIDs...)
+	}
+}
+
+// WithBitwardenKeyPrefix makes the Bitwarden reader only read secrets whose key starts with prefix.
+// The prefix is stripped from the keys, so with the prefix "myapp_", the secret "myapp_db_password"
+// is read as "db_password"
+func WithBitwardenKeyPrefix(prefix string) func(*bitwardenSecretReader) {
+	return func(r *bitwardenSecretReader) {
+		r.keyPrefix = prefix
+	}
+}
+
+// WithBitwardenTarget makes the Bitwarden reader only read secrets whose key (after stripping
+// the key prefix) matches one of the fields of target
+// This should be the same struct that you pass to Unmarshal
+func WithBitwardenTarget(target any) func(*bitwardenSecretReader) {
+	return func(r *bitwardenSecretReader) {
+		r.target = target
+	}
+}
+
+// WithBitwardenBatchSize sets how many secrets the Bitwarden reader fetches per request
+// By default, it is 50
+func WithBitwardenBatchSize(batchSize int) func(*bitwardenSecretReader) {
+	return func(r *bitwardenSecretReader) {
+		r.fetchOptions.batchSize = batchSize
+	}
+}
+
+// WithBitwardenConcurrency sets how many requests the Bitwarden reader makes at the same time
+// By default, it is 4
+func WithBitwardenConcurrency(concurrency int) func(*bitwardenSecretReader) {
+	return func(r *bitwardenSecretReader) {
+		r.fetchOptions.concurrency = concurrency
+	}
+}
+
+// WithBitwardenRetries sets how many times the Bitwarden reader retries a failed request.
+// The wait between retries starts at backoff and doubles after each attempt
+// By default, it retries twice, starting with a wait of 500ms
+func WithBitwardenRetries(retries int, backoff time.Duration) func(*bitwardenSecretReader) {
+	return func(r *bitwardenSecretReader) {
+		r.fetchOptions.retries = retries
+		r.fetchOptions.backoff = backoff
+	}
+}
+
+// WithBitwardenClientFactory replaces the function that creates the Bitwarden client.
+// The factory receives the credentials that were read from the config and returns
+// the SecretStore to read secrets from.
+// This is useful to inject an in-memory fake in tests, or to read from an alternate backend
+func WithBitwardenClientFactory(factory func(BitwardenCredentials) (SecretStore, error)) func(*bitwardenSecretReader) {
+	return func(r *bitwardenSecretReader) {
+		r.newStore = factory
+	}
+}
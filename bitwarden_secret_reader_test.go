@@ -0,0 +1,227 @@
+package conflux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var _ SecretStore = (*fakeSecretStore)(nil)
+
+// fakeSecretStore is an in-memory SecretStore
+// every call sleeps for latency to simulate a network round-trip
+type fakeSecretStore struct {
+	secrets  []SecretValue
+	latency  time.Duration
+	failures atomic.Int32
+	// failure is returned by Get while there are failures left. By default, it is a transient error
+	failure error
+	gets    atomic.Int32
+}
+
+func newFakeSecretStore(n int, latency time.Duration) *fakeSecretStore {
+	store := &fakeSecretStore{latency: latency}
+	for i := range n {
+		store.secrets = append(store.secrets, SecretValue{
+			ID:        fmt.Sprintf("id-%d", i),
+			Key:       fmt.Sprintf("app_key_%d", i),
+			Value:     fmt.Sprintf("value-%d", i),
+			ProjectID: fmt.Sprintf("project-%d", i%2),
+		})
+	}
+	return store
+}
+
+func (s *fakeSecretStore) List() ([]SecretIdentifier, error) {
+	time.Sleep(s.latency)
+	identifiers := make([]SecretIdentifier, 0, len(s.secrets))
+	for _, secret := range s.secrets {
+		identifiers = append(identifiers, SecretIdentifier{ID: secret.ID, Key: secret.Key})
+	}
+	return identifiers, nil
+}
+
+func (s *fakeSecretStore) Get(ids []string) ([]SecretValue, error) {
+	time.Sleep(s.latency)
+	s.gets.Add(1)
+	if s.failures.Add(-1) >= 0 {
+		if s.failure != nil {
+			return nil, s.failure
+		}
+		return nil, errors.New("transient error")
+	}
+
+	var secrets []SecretValue
+	for _, id := range ids {
+		for _, secret := range s.secrets {
+			if secret.ID == id {
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+	return secrets, nil
+}
+
+func (s *fakeSecretStore) factory(creds BitwardenCredentials) (SecretStore, error) {
+	if creds.AccessToken != "valid" {
+		return nil, fmt.Errorf("%w: invalid access token", ErrAuthentication)
+	}
+	return s, nil
+}
+
+func TestBitwardenSecretReader(t *testing.T) {
+	cases := []struct {
+		name     string
+		env      []string
+		opts     []func(*bitwardenSecretReader)
+		failures int32
+		expected map[string]string
+		absent   []string
+	}{
+		{
+			name:     "all secrets",
+			env:      []string{"BITWARDEN_ACCESS_TOKEN=valid", "BITWARDEN_ORGANIZATION_ID=org"},
+			expected: map[string]string{"app_key_0": "value-0", "app_key_1": "value-1", "app_key_2": "value-2"},
+		},
+		{
+			name:     "project from config and prefix",
+			env:      []string{"BITWARDEN_ACCESS_TOKEN=valid", "BITWARDEN_ORGANIZATION_ID=org", "BITWARDEN_PROJECT_ID=project-0"},
+			opts:     []func(*bitwardenSecretReader){WithBitwardenKeyPrefix("app_")},
+			expected: map[string]string{"key_0": "value-0", "key_2": "value-2"},
+			absent:   []string{"key_1", "app_key_0"},
+		},
+		{
+			name:     "retries transient errors",
+			env:      []string{"BITWARDEN_ACCESS_TOKEN=valid", "BITWARDEN_ORGANIZATION_ID=org"},
+			opts:     []func(*bitwardenSecretReader){WithBitwardenRetries(2, time.Millisecond)},
+			failures: 2,
+			expected: map[string]string{"app_key_0": "value-0", "app_key_1": "value-1", "app_key_2": "value-2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeSecretStore(3, 0)
+			store.failures.Store(tc.failures)
+
+			opts := append([]func(*bitwardenSecretReader){WithBitwardenClientFactory(store.factory)}, tc.opts...)
+			r := NewConfigMux(
+				WithEnvReader(WithEnviron(tc.env)),
+				WithBitwardenSecretReader(opts...),
+			)
+
+			configMap := make(map[string]string)
+			if _, err := Unmarshal(r, &configMap); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for k, v := range tc.expected {
+				if configMap[k] != v {
+					t.Errorf("expected %s to be %s, got %s", k, v, configMap[k])
+				}
+			}
+			for _, k := range tc.absent {
+				if _, ok := configMap[k]; ok {
+					t.Errorf("expected %s to be filtered out: %v", k, configMap)
+				}
+			}
+		})
+	}
+}
+
+func TestBitwardenSecretReader_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		env           []string
+		failures      int32
+		expectedError string
+	}{
+		{
+			name:          "authentication failure",
+			env:           []string{"BITWARDEN_ACCESS_TOKEN=invalid", "BITWARDEN_ORGANIZATION_ID=org"},
+			expectedError: "invalid access token",
+		},
+		{
+			name:          "partial failure",
+			env:           []string{"BITWARDEN_ACCESS_TOKEN=valid", "BITWARDEN_ORGANIZATION_ID=org"},
+			failures:      1,
+			expectedError: "transient error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeSecretStore(3, 0)
+			store.failures.Store(tc.failures)
+
+			r := NewConfigMux(
+				WithEnvReader(WithEnviron(tc.env)),
+				WithBitwardenSecretReader(
+					WithBitwardenClientFactory(store.factory),
+					WithBitwardenBatchSize(2),
+					WithBitwardenRetries(0, 0),
+				),
+			)
+
+			configMap := make(map[string]string)
+			if _, err := Unmarshal(r, &configMap); err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Fatalf("expected error containing %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestBitwardenSecretReader_Credentials(t *testing.T) {
+	cases := []struct {
+		name               string
+		env                []string
+		expected           BitwardenCredentials
+		expectedDiagnostic string
+	}{
+		{
+			name:               "bitwarden names",
+			env:                []string{"BITWARDEN_ACCESS_TOKEN=valid", "BITWARDEN_ORGANIZATION_ID=org"},
+			expected:           BitwardenCredentials{APIURL: "https://api.bitwarden.com", IdentityURL: "https://identity.bitwarden.com", AccessToken: "valid", OrganizationID: "org", StateFilePath: ".bw_state"},
+			expectedDiagnostic: "Loaded: using bitwarden_access_token",
+		},
+		{
+			name:               "bws names",
+			env:                []string{"BWS_ACCESS_TOKEN=valid", "BWS_SERVER_URL=https://vault.example.com/", "BITWARDEN_ORGANIZATION_ID=org"},
+			expected:           BitwardenCredentials{APIURL: "https://vault.example.com/api", IdentityURL: "https://vault.example.com/identity", AccessToken: "valid", OrganizationID: "org", StateFilePath: ".bw_state"},
+			expectedDiagnostic: "Loaded: using bws_access_token, bws_server_url",
+		},
+		{
+			name:               "bitwarden names take precedence",
+			env:                []string{"BITWARDEN_ACCESS_TOKEN=valid", "BWS_ACCESS_TOKEN=other", "BITWARDEN_API_URL=https://api.example.com", "BITWARDEN_IDENTITY_URL=https://identity.example.com", "BWS_SERVER_URL=https://vault.example.com", "BITWARDEN_ORGANIZATION_ID=org"},
+			expected:           BitwardenCredentials{APIURL: "https://api.example.com", IdentityURL: "https://identity.example.com", AccessToken: "valid", OrganizationID: "org", StateFilePath: ".bw_state"},
+			expectedDiagnostic: "Loaded: using bitwarden_access_token",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var creds BitwardenCredentials
+			r := NewConfigMux(
+				WithEnvReader(WithEnviron(tc.env)),
+				WithBitwardenSecretReader(WithBitwardenClientFactory(func(c BitwardenCredentials) (SecretStore, error) {
+					creds = c
+					return newFakeSecretStore(0, 0), nil
+				})),
+			)
+
+			configMap := make(map[string]string)
+			diagnostics, err := Unmarshal(r, &configMap)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds != tc.expected {
+				t.Errorf("expected credentials %+v, got %+v", tc.expected, creds)
+			}
+			if diagnostics["bitwarden #2"] != tc.expectedDiagnostic {
+				t.Errorf("expected diagnostics[\"bitwarden #2\"] to be %q, got %q", tc.expectedDiagnostic, diagnostics["bitwarden #2"])
+			}
+		})
+	}
+}
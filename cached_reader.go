@@ -0,0 +1,243 @@
+package conflux
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+var _ Reader = (*cachedReader)(nil)
+
+type cachedReader struct {
+	reader       Reader
+	path         string
+	ttl          time.Duration
+	key          string
+	keyConfigKey string
+	configMap    map[string]string
+	// mapper is the NameMapper that the keys of configMap were converted with, if any
+	mapper NameMapper
+	now    func() time.Time
+}
+
+type cacheEntry struct {
+	SavedAt   time.Time         `json:"saved_at"`
+	ConfigMap map[string]string `json:"config_map"`
+}
+
+// cacheMagic prefixes every cache file so that files written in another format are rejected
+const cacheMagic = "cfx1"
+
+// cacheSaltSize is the size of the random salt that is stored in the cache file header
+const cacheSaltSize = 16
+
+// defaultCacheKeyConfigKey is the config key that the cache key is read from by default
+const defaultCacheKeyConfigKey = "conflux_cache_key"
+
+// NewCachedReader wraps a reader so that its last successful result is stored in an encrypted file at path.
+// If the cached result is younger than the TTL (see WithCacheTTL), it is returned without calling the reader.
+// If the reader fails, the cached result is returned regardless of its age. Either way, a diagnostic
+// keyed by path reports that the values came from the cache.
+// The file is encrypted with a key that is set with WithCacheKey, or read from the config
+// with WithCacheKeyFromConfig. If there is no key, the cache is skipped.
+func NewCachedReader(reader Reader, path string, opts ...func(*cachedReader)) *cachedReader {
+	r := cachedReader{
+		reader:       reader,
+		path:         path,
+		keyConfigKey: defaultCacheKeyConfigKey,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	return &r
+}
+
+func (r *cachedReader) Read() (ReadResult, error) {
+	key := r.key
+	if key == "" {
+		key = r.lookupKey()
+	}
+	if key == "" {
+		return r.reader.Read()
+	}
+
+	entry, cacheErr := r.load(key)
+	if cacheErr == nil && r.now().Sub(entry.SavedAt) < r.ttl {
+		return NewDiagnosticReadResult(entry.ConfigMap, map[string]string{r.path: "Loaded: From Cache"}), nil
+	}
+
+	readResult, err := r.reader.Read()
+	if errors.Is(err, ErrInvalidFields) {
+		return readResult, err
+	} else if err != nil {
+		if cacheErr != nil {
+			return nil, err
+		}
+		diagnostics := map[string]string{r.path: fmt.Sprintf("Loaded: From Stale Cache (%v)", err)}
+		return NewDiagnosticReadResult(entry.ConfigMap, diagnostics), nil
+	}
+
+	if err := r.save(key, cacheEntry{SavedAt: r.now(), ConfigMap: readResult.GetConfigMap()}); err != nil {
+		return nil, fmt.Errorf("error saving cache (%s): %w", r.path, err)
+	}
+
+	return readResult, nil
+}
+
+func (r *cachedReader) load(key string) (cacheEntry, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return cacheEntry{}, fmt.Errorf("error reading cache file: %w", err)
+	}
+
+	// the file is laid out as magic | salt | nonce | ciphertext
+	header := len(cacheMagic) + cacheSaltSize
+	if len(data) < header || string(data[:len(cacheMagic)]) != cacheMagic {
+		return cacheEntry{}, fmt.Errorf("cache file has an unknown format")
+	}
+	salt, data := data[len(cacheMagic):header], data[header:]
+
+	gcm, err := newGCM(key, salt)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return cacheEntry{}, fmt.Errorf("cache file is too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return cacheEntry{}, fmt.Errorf("error decrypting cache file: %w", err)
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(plaintext, &entry); err != nil {
+		return cacheEntry{}, fmt.Errorf("error unmarshalling cache file: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (r *cachedReader) save(key string, entry cacheEntry) error {
+	entry.ConfigMap = maps.Clone(entry.ConfigMap)
+	plaintext, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshalling cache entry: %w", err)
+	}
+
+	salt := make([]byte, cacheSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("error generating salt: %w", err)
+	}
+
+	gcm, err := newGCM(key, salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	data := append([]byte(cacheMagic), salt...)
+	data = append(data, nonce...)
+	data = gcm.Seal(data, nonce, plaintext, nil)
+
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("error creating cache directory: %w", err)
+	}
+
+	// write to a temporary file first so that a crash doesn't leave a corrupted cache behind
+	tmp, err := os.CreateTemp(dir, filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// newGCM derives an AES-256-GCM cipher from key and salt with scrypt
+func newGCM(key string, salt []byte) (cipher.AEAD, error) {
+	derived, err := scrypt.Key([]byte(key), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcm: %w", err)
+	}
+
+	return gcm, nil
+}
+
+// lookupKey returns the cache key from the config
+// the config of a lazy reader has keys that were converted by the NameMapper of the mux, like conflux-cache-key
+func (r *cachedReader) lookupKey() string {
+	if key, ok := lookupKey(r.configMap, r.keyConfigKey); ok || r.mapper == nil {
+		return key
+	}
+	key, _ := lookupKey(r.configMap, r.mapper(r.keyConfigKey))
+	return key
+}
+
+func (r *cachedReader) setFieldNameMapper(mapper NameMapper) {
+	r.mapper = mapper
+	if reader, ok := r.reader.(fieldMapped); ok {
+		reader.setFieldNameMapper(mapper)
+	}
+}
+
+// WithCacheTTL sets how long a cached result is used before the wrapped reader is called again
+// By default, it is 0, which means that the cache is only used when the wrapped reader fails
+func WithCacheTTL(ttl time.Duration) func(*cachedReader) {
+	return func(r *cachedReader) {
+		r.ttl = ttl
+	}
+}
+
+// WithCacheKey sets the key that the cache file is encrypted with
+func WithCacheKey(key string) func(*cachedReader) {
+	return func(r *cachedReader) {
+		r.key = key
+	}
+}
+
+// WithCacheKeyFromConfig sets the config key whose value the cache file is encrypted with
+// This is only used when the cache is added with WithCache, and when WithCacheKey isn't used
+// By default, it is conflux_cache_key
+func WithCacheKeyFromConfig(configKey string) func(*cachedReader) {
+	return func(r *cachedReader) {
+		r.keyConfigKey = configKey
+	}
+}
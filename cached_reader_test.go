@@ -0,0 +1,183 @@
+package conflux
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCachedReader(t *testing.T) {
+	env := []string{"BITWARDEN_ACCESS_TOKEN=valid", "BITWARDEN_ORGANIZATION_ID=org", "CONFLUX_CACHE_KEY=s3cr3t"}
+
+	newMux := func(path string, store *fakeSecretStore, ttl time.Duration) *ConfigMux {
+		return NewConfigMux(
+			WithEnvReader(WithEnviron(env)),
+			WithCache(path, WithBitwardenSecretReader(
+				WithBitwardenClientFactory(store.factory),
+				WithBitwardenRetries(0, 0),
+			), WithCacheTTL(ttl)),
+		)
+	}
+
+	cases := []struct {
+		name               string
+		populated          bool
+		ttl                time.Duration
+		failures           int32
+		expectedDiagnostic string
+	}{
+		{name: "first read populates the cache", expectedDiagnostic: ""},
+		{name: "fresh cache is served without calling the reader", populated: true, ttl: time.Hour, failures: 1, expectedDiagnostic: "Loaded: From Cache"},
+		{name: "stale cache is served when the reader fails", populated: true, failures: 1, expectedDiagnostic: "Loaded: From Stale Cache"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "cache", "bitwarden")
+			store := newFakeSecretStore(1, 0)
+
+			if tc.populated {
+				if _, err := Unmarshal(newMux(path, store, 0), &map[string]string{}); err != nil {
+					t.Fatalf("unexpected error populating the cache: %v", err)
+				}
+			}
+
+			store.failures.Store(tc.failures)
+
+			configMap := make(map[string]string)
+			diagnostics, err := Unmarshal(newMux(path, store, tc.ttl), &configMap)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if configMap["app_key_0"] != "value-0" {
+				t.Errorf("expected app_key_0 to be value-0, got %s", configMap["app_key_0"])
+			}
+			if !strings.HasPrefix(diagnostics[path], tc.expectedDiagnostic) || (tc.expectedDiagnostic == "" && diagnostics[path] != "") {
+				t.Errorf("expected diagnostic %q, got %q", tc.expectedDiagnostic, diagnostics[path])
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("unexpected error reading cache file: %v", err)
+			}
+			if bytes.Contains(data, []byte("value-0")) {
+				t.Errorf("expected cache file to be encrypted")
+			}
+
+			entries, err := os.ReadDir(filepath.Dir(path))
+			if err != nil {
+				t.Fatalf("unexpected error reading cache directory: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("expected only the cache file to be left behind, got %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestCachedReader_Salt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	r := NewCachedReader(nil, path)
+
+	entry := cacheEntry{SavedAt: time.Now(), ConfigMap: map[string]string{"a": "b"}}
+	if err := r.save("s3cr3t", entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first, _ := os.ReadFile(path)
+	if err := r.save("s3cr3t", entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _ := os.ReadFile(path)
+
+	header := len(cacheMagic) + cacheSaltSize
+	if bytes.Equal(first[len(cacheMagic):header], second[len(cacheMagic):header]) {
+		t.Errorf("expected every save to use a new salt")
+	}
+
+	if _, err := r.load("wrong"); err == nil {
+		t.Errorf("expected an error loading the cache with the wrong key")
+	}
+	loaded, err := r.load("s3cr3t")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.ConfigMap["a"] != "b" {
+		t.Errorf("expected a to be b, got %q", loaded.ConfigMap["a"])
+	}
+}
+
+func TestWithCache_DependentReader(t *testing.T) {
+	t.Run("is skipped when it is not configured", func(t *testing.T) {
+		configMux := NewConfigMux(
+			WithEnvReader(WithEnviron([]string{"CONFLUX_CACHE_KEY=s3cr3t"})),
+			WithCache(filepath.Join(t.TempDir(), "cache"), WithBitwardenSecretReader()),
+		)
+
+		configMap := make(map[string]string)
+		diagnostics, err := Unmarshal(configMux, &configMap)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(diagnostics["bitwarden #2"], StatusSkipped) {
+			t.Errorf("expected bitwarden to be skipped, got %v", diagnostics)
+		}
+	})
+
+	t.Run("is an error when it is mandatory", func(t *testing.T) {
+		configMux := NewConfigMux(
+			WithCache(filepath.Join(t.TempDir(), "cache"), WithMandatoryReader(WithBitwardenSecretReader())),
+		)
+
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(configMux, &configMap); !errors.Is(err, ErrNotConfigured) {
+			t.Errorf("expected error to match %v, got %v", ErrNotConfigured, err)
+		}
+	})
+
+	t.Run("only gets its inputs", func(t *testing.T) {
+		var received map[string]string
+		configMux := NewConfigMux(
+			WithEnvReader(WithEnviron([]string{"API_TOKEN=abc", "DB_PASSWORD=hunter2", "CONFLUX_CACHE_KEY=s3cr3t"})),
+			WithCache(filepath.Join(t.TempDir(), "cache"), WithCustomDependentReader([]string{"api_token"}, func(configMap map[string]string) Reader {
+				received = maps.Clone(configMap)
+				return newMapReader(map[string]string{"ssh_port": "22"})
+			})),
+		)
+
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(configMux, &configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := map[string]string{"api_token": "abc"}; !maps.Equal(received, expected) {
+			t.Errorf("expected reader to get %v, got %v", expected, received)
+		}
+		if configMap["ssh_port"] != "22" {
+			t.Errorf("expected ssh_port to be 22, got %v", configMap)
+		}
+	})
+}
+
+func TestWithCache_NameMapper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	configMux := NewConfigMux(
+		WithEnvReader(WithEnviron([]string{"CONFLUX_CACHE_KEY=s3cr3t"})),
+		WithCache(path, WithCustomLazyReader(func(configMap map[string]string) Reader {
+			return newMapReader(map[string]string{"ssh_port": "22"})
+		})),
+		WithNameMapper(KebabCase),
+	)
+
+	configMap := make(map[string]string)
+	if _, err := Unmarshal(configMux, &configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the cache key to be found as conflux-cache-key and the cache to be saved, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package conflux
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// CheckIssueKind is the kind of problem that Check found
+type CheckIssueKind string
+
+const (
+	// CheckMissing is a required key that isn't set by any file
+	CheckMissing CheckIssueKind = "missing"
+	// CheckUnknown is a key in a file that doesn't match any field of the target or any key that a reader needs
+	CheckUnknown CheckIssueKind = "unknown"
+	// CheckInvalid is a key whose value doesn't pass validation, or a file that can't be parsed
+	CheckInvalid CheckIssueKind = "invalid"
+)
+
+// CheckIssue is a problem that Check found in config files
+type CheckIssue struct {
+	Kind CheckIssueKind
+	Key  string
+	// Path is the file where the key was set. It is empty for missing keys
+	Path string
+	// Line and Column are the 1-based position of the key in Path
+	Line    int
+	Column  int
+	Message string
+}
+
+// String formats the issue like a compiler error, so that editors and CI can link to it
+func (i CheckIssue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", i.Path, i.Line, i.Column, i.Message)
+}
+
+// Check lints the config files of the file-based readers of opts against target, without reading
+// any other source. For example, Check(&cfg, WithYAMLFileReader("config/"), WithBitwardenSecretReader())
+// only reads the files in config/.
+// It reports required keys that no file sets, keys that don't match a field of target, and values
+// that don't pass validation, along with the file, line and column where each key was set.
+// Keys that the other readers of opts declared, like bitwarden_access_token or the keys passed to
+// WithCustomDependentReader, are not reported as unknown
+func Check(target any, opts ...func(*ConfigMux)) ([]CheckIssue, error) {
+	configMux := ConfigMux{}
+	for _, opt := range opts {
+		opt(&configMux)
+	}
+
+	fields, err := getMappedFields(target, configMux.nameMapper)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tagged fields: %w", err)
+	}
+
+	known := map[string]bool{defaultCacheKeyConfigKey: true}
+	for _, field := range fields {
+		if field.Type.IsExported() && isStringField(field.Type) {
+			known[strings.ToLower(field.tag)] = true
+		}
+	}
+
+	var issues, unknown []CheckIssue
+	var unknownKeys []string
+	configMap := make(map[string]string)
+	positions := make(map[string]CheckIssue)
+	for _, muxReader := range configMux.readers {
+		// the keys that a reader declared, like the header keys of an http reader, are read from these files too
+		for _, key := range muxReader.inputs {
+			if configMux.nameMapper != nil {
+				key = configMux.nameMapper(key)
+			}
+			known[strings.ToLower(key)] = true
+		}
+
+		// only file-based readers are read, and they don't depend on previously read values
+		reader, ok := muxReader.newReader(nil).(*yamlFileReader)
+		if !ok {
+			continue
+		}
+
+		files, _, err := reader.files()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			fileIssues, fileKeys, err := checkYAMLFile(reader.fileSystem, file)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, fileIssues...)
+			mapper := configMux.readerNameMapper(muxReader)
+			for _, fileKey := range fileKeys {
+				key := fileKey.Key
+				if mapper != nil {
+					key = mapper(key)
+				}
+				positions[strings.ToLower(key)] = fileKey
+				if !known[strings.ToLower(key)] {
+					unknown, unknownKeys = append(unknown, fileKey), append(unknownKeys, strings.ToLower(key))
+				}
+			}
+
+			fileConfig, err := reader.readFile(file)
+			if err != nil {
+				// the problem was already reported by checkYAMLFile
+				continue
+			}
+			for k, v := range fileConfig {
+				if mapper != nil {
+					k = mapper(k)
+				}
+				configMap[strings.ToLower(k)] = v
+			}
+		}
+	}
+
+	// unknown keys are only reported once every reader was seen, because the keys that a reader needs
+	// are usually set in files that come before it
+	for i, issue := range unknown {
+		if !known[unknownKeys[i]] {
+			issue.Kind, issue.Message = CheckUnknown, fmt.Sprintf("unknown key %s", issue.Key)
+			issues = append(issues, issue)
+		}
+	}
+
+	// fill a new value of the target's type, so that its Validate method is run too
+	filled := reflect.New(reflect.Indirect(reflect.ValueOf(target)).Type())
+	if err := fromMap(configMap, filled.Interface(), configMux.nameMapper); err != nil {
+		return nil, fmt.Errorf("error converting map into target: %w", err)
+	}
+	diagnostics, err := validateStruct(filled.Interface(), configMux.nameMapper)
+	if err != nil && !errors.Is(err, ErrInvalidFields) {
+		return nil, fmt.Errorf("error validating target: %w", err)
+	}
+
+	for key, status := range diagnostics {
+		switch {
+		case status == StatusLoaded:
+		case status == StatusMissing:
+			issues = append(issues, CheckIssue{Kind: CheckMissing, Key: key, Message: fmt.Sprintf("missing required key %s", key)})
+		default:
+			issue := positions[strings.ToLower(key)]
+			issue.Kind, issue.Key = CheckInvalid, key
+			issue.Message = fmt.Sprintf("invalid value for %s: %s", key, status)
+			issues = append(issues, issue)
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		} else if a.Line != b.Line {
+			return a.Line < b.Line
+		} else if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Key < b.Key
+	})
+
+	return issues, nil
+}
+
+// checkYAMLFile parses a yaml file and returns each of its top-level keys, with their position.
+// values that can't be read as a string, and syntax errors, are returned as issues
+func checkYAMLFile(fileSystem fs.FS, path string) ([]CheckIssue, []CheckIssue, error) {
+	data, err := fs.ReadFile(fileSystem, path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading config file(%s): %w", path, err)
+	}
+
+	file, err := parser.ParseBytes(data, 0)
+	if err != nil {
+		parseErr := NewFileParseError(path, data, err)
+		return []CheckIssue{{Kind: CheckInvalid, Path: path, Line: max(parseErr.Line, 1), Column: max(parseErr.Column, 1), Message: parseErr.message()}}, nil, nil
+	}
+
+	var issues, keys []CheckIssue
+	for _, doc := range file.Docs {
+		var values []*ast.MappingValueNode
+		switch body := doc.Body.(type) {
+		case nil:
+			continue
+		case *ast.MappingNode:
+			values = body.Values
+		case *ast.MappingValueNode:
+			values = []*ast.MappingValueNode{body}
+		default:
+			position := body.GetToken().Position
+			issues = append(issues, CheckIssue{Kind: CheckInvalid, Path: path, Line: position.Line, Column: position.Column, Message: "config file must be a map of keys to values"})
+			continue
+		}
+
+		for _, value := range values {
+			position := value.Key.GetToken().Position
+			key := value.Key.GetToken().Value
+			keys = append(keys, CheckIssue{Key: key, Path: path, Line: position.Line, Column: position.Column})
+
+			switch value.Value.Type() {
+			case ast.MappingType, ast.SequenceType:
+				issues = append(issues, CheckIssue{Kind: CheckInvalid, Key: key, Path: path, Line: position.Line, Column: position.Column, Message: fmt.Sprintf("value of %s must be a string, not a %s", key, value.Value.Type().YAMLName())})
+			}
+		}
+	}
+
+	return issues, keys, nil
+}
@@ -0,0 +1,79 @@
+package conflux
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheck(t *testing.T) {
+	fileSystem := fstest.MapFS{
+		"config/all.yml":  {Data: []byte("ssh_port: 22\nlog_level: verbose\nbitwarden_access_token: x\n")},
+		"config/prod.yml": {Data: []byte("# production\nsssh_port: 2222\ndb:\n  host: localhost\n")},
+		"broken.yml":      {Data: []byte("ssh_port: 22\nlog_level: [info\n")},
+		"readers.yml":     {Data: []byte("ssh_port: 22\nconfig_token: abc\ntenant_id: acme\napi_token: def\n")},
+	}
+
+	cases := []struct {
+		name     string
+		opts     []func(*ConfigMux)
+		expected []string
+	}{
+		{
+			name: "missing, unknown and invalid keys",
+			opts: []func(*ConfigMux){
+				WithYAMLFileReader("config", WithFileSystem(fileSystem)),
+				WithEnvReader(WithEnviron([]string{"DB_PASSWORD=hunter2"})),
+				WithBitwardenSecretReader(),
+			},
+			expected: []string{
+				"missing required key db_password",
+				"config/all.yml:2:1: invalid value for log_level: invalid: must be one of debug, info, warn",
+				"config/prod.yml:2:1: unknown key sssh_port",
+				"config/prod.yml:3:1: value of db must be a string, not a mapping",
+				"config/prod.yml:3:1: unknown key db",
+			},
+		},
+		{
+			name: "keys of readers that aren't added are unknown",
+			opts: []func(*ConfigMux){WithYAMLFileReader("config/all.yml", WithFileSystem(fileSystem))},
+			expected: []string{
+				"missing required key db_password",
+				"config/all.yml:2:1: invalid value for log_level: invalid: must be one of debug, info, warn",
+				"config/all.yml:3:1: unknown key bitwarden_access_token",
+			},
+		},
+		{
+			name: "keys that readers declared are known",
+			opts: []func(*ConfigMux){
+				WithYAMLFileReader("readers.yml", WithFileSystem(fileSystem)),
+				WithHTTPReader("https://config.example.com", WithBearerTokenFromConfig("config_token"), WithHeaderFromConfig("X-Tenant", "tenant_id")),
+				WithCustomDependentReader([]string{"api_token"}, func(configMap map[string]string) Reader { return newMapReader(nil) }),
+			},
+			expected: []string{"missing required key db_password"},
+		},
+		{
+			name:     "syntax error",
+			opts:     []func(*ConfigMux){WithYAMLFileReader("broken.yml", WithFileSystem(fileSystem))},
+			expected: []string{"missing required key db_password", "missing required key ssh_port", "broken.yml:2:"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues, err := Check(&schemaConfig{}, tc.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(issues) != len(tc.expected) {
+				t.Fatalf("expected %d issues, got %d: %v", len(tc.expected), len(issues), issues)
+			}
+			for i, expected := range tc.expected {
+				if got := issues[i].String(); !strings.HasPrefix(got, expected) {
+					t.Errorf("expected issue %d to start with %q, got %q", i, expected, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/dannyvelas/conflux"
+)
+
+func runCheck(m manifest, args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintf(stderr, "conflux: check takes no arguments\n")
+		return 2
+	}
+
+	if len(m.Keys) == 0 {
+		fmt.Fprintf(stderr, "conflux: the manifest has no keys to check the config files against\n")
+		return 1
+	}
+
+	opts := make([]func(*conflux.ConfigMux), 0, len(m.Readers))
+	for i, spec := range m.Readers {
+		opt, err := spec.option()
+		if err != nil {
+			fmt.Fprintf(stderr, "conflux: error in reader %d: %v\n", i, err)
+			return 1
+		}
+		opts = append(opts, opt)
+	}
+
+	issues, err := conflux.Check(m.target(), opts...)
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+	}
+
+	if len(issues) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// target returns a pointer to a new struct with a field for each key of the manifest,
+// so that the manifest can be used where conflux expects a config struct
+func (m manifest) target() any {
+	fields := make([]reflect.StructField, 0, len(m.Keys))
+	for i, key := range m.Keys {
+		tag := `json:` + strconv.Quote(key.Name)
+		if key.Required {
+			tag += ` required:"true"`
+		}
+		if key.Secret {
+			tag += ` secret:"true"`
+		}
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("Key%d", i),
+			Type: reflect.TypeFor[string](),
+			Tag:  reflect.StructTag(tag),
+		})
+	}
+	return reflect.New(reflect.StructOf(fields)).Interface()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/dannyvelas/conflux"
+)
+
+// stringsFlag is a flag that can be passed more than once
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func runExec(manifestPath string, args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("exec", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	var yamlPaths stringsFlag
+	flagSet.Var(&yamlPaths, "yaml", "yaml file or directory to read, from lowest to highest priority (repeatable)")
+	withEnv := flagSet.Bool("env", false, "read environment variables")
+	withBitwarden := flagSet.Bool("bitwarden", false, "read secrets from Bitwarden")
+	withVault := flagSet.Bool("vault", false, "read secrets from HashiCorp Vault")
+	withAWS := flagSet.Bool("aws", false, "read secrets from AWS")
+	prefix := flagSet.String("prefix", "", "prefix of the exported environment variables")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+
+	if flagSet.NArg() == 0 {
+		fmt.Fprintf(stderr, "conflux: exec needs a command to run\n")
+		return 2
+	}
+
+	// readers passed as flags replace the readers of the manifest, in which case
+	// the manifest is optional and only used for its keys
+	var readers []readerSpec
+	if len(yamlPaths) > 0 {
+		readers = append(readers, readerSpec{Type: "yaml", Paths: yamlPaths})
+	}
+	for _, r := range []struct {
+		enabled    bool
+		readerType string
+	}{{*withEnv, "env"}, {*withBitwarden, "bitwarden"}, {*withVault, "vault"}, {*withAWS, "aws"}} {
+		if r.enabled {
+			readers = append(readers, readerSpec{Type: r.readerType})
+		}
+	}
+
+	var (
+		m   manifest
+		err error
+	)
+	if len(readers) == 0 {
+		m, err = loadManifest(manifestPath)
+	} else if m, err = parseManifest(manifestPath); errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+	if len(readers) > 0 {
+		m.Readers = readers
+	}
+
+	result, configMap, _, err := m.read()
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	if missing := m.missingKeys(configMap); len(missing) > 0 {
+		fmt.Fprintf(stderr, "conflux: refusing to run %s, missing required keys: %s\n", flagSet.Arg(0), strings.Join(missing, ", "))
+		return 1
+	}
+
+	// values that were read from the environment are already there, unless the manifest asks for them
+	sources := result.Sources()
+	exported := make(map[string]string, len(configMap))
+	for key, value := range configMap {
+		if sources[key].Reader != "env" {
+			exported[*prefix+conflux.EnvVarName(key)] = value
+		}
+	}
+	for _, key := range m.Keys {
+		if value, ok := configMap[strings.ToLower(key.Name)]; ok {
+			exported[*prefix+conflux.EnvVarName(key.Name)] = value
+		}
+	}
+
+	cmd := exec.Command(flagSet.Arg(0), flagSet.Args()[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, stdout, stderr
+	cmd.Env = environ(os.Environ(), exported)
+
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(stderr, "conflux: error starting %s: %v\n", flagSet.Arg(0), err)
+		return 127
+	}
+
+	// forward signals so that the child can shut down gracefully
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(stderr, "conflux: error running %s: %v\n", flagSet.Arg(0), err)
+		return 1
+	}
+
+	return 0
+}
+
+// environ returns base with the variables of exported added, replacing any variables with the same name
+func environ(base []string, exported map[string]string) []string {
+	env := make([]string, 0, len(base)+len(exported))
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := exported[name]; !ok {
+			env = append(env, kv)
+		}
+	}
+
+	names := make([]string, 0, len(exported))
+	for name := range exported {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+exported[name])
+	}
+
+	return env
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+func runExplain(m manifest, args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("explain", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	all := flagSet.Bool("all", false, "explain a key even if the manifest doesn't declare it")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() != 1 {
+		fmt.Fprintf(stderr, "Usage: conflux explain [-all] KEY\n")
+		return 2
+	}
+	key := flagSet.Arg(0)
+
+	// only keys of the manifest are explained, so that other values of the environment aren't printed by accident
+	if !*all && !m.hasKey(key) {
+		fmt.Fprintf(stderr, "conflux: %s is not a key of the manifest, pass -all to explain it anyway\n", key)
+		return 1
+	}
+
+	result, _, _, err := m.read()
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	values := result.Explain(key)
+	if len(values) == 0 {
+		fmt.Fprintf(stderr, "conflux: %s is not set by any reader\n", key)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tVALUE\t")
+	for i, v := range values {
+		value := v.Value
+		if v.Source.Secret || m.isSecretKey(key) {
+			value = "[REDACTED]"
+		}
+
+		used := ""
+		if i == len(values)-1 {
+			used = "(used)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Source.Reader, value, used)
+	}
+	w.Flush()
+
+	return 0
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dannyvelas/conflux"
+)
+
+func runExport(m manifest, args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("export", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	format := flagSet.String("format", "dotenv", "output format: dotenv, shell, yaml, yaml-nested or json")
+	prefix := flagSet.String("prefix", "", "prefix of the variable names of the dotenv and shell formats")
+	output := flagSet.String("o", "", "file to write to instead of stdout. it is created with mode 0600 because it contains secrets")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+
+	_, configMap, _, err := m.read()
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	if missing := m.missingKeys(configMap); len(missing) > 0 {
+		fmt.Fprintf(stderr, "conflux: missing required keys: %s\n", strings.Join(missing, ", "))
+		return 1
+	}
+
+	// only export the keys of the manifest so that the whole environment isn't exported
+	if len(m.Keys) > 0 {
+		manifestMap := make(map[string]string, len(m.Keys))
+		for _, key := range m.Keys {
+			if value, ok := configMap[strings.ToLower(key.Name)]; ok {
+				manifestMap[key.Name] = value
+			}
+		}
+		configMap = manifestMap
+	}
+
+	out, err := conflux.ExportMap(configMap, conflux.ExportOptions{Format: conflux.ExportFormat(*format), Prefix: *prefix})
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	if *output == "" {
+		fmt.Fprint(stdout, out)
+		return 0
+	}
+
+	if err := os.WriteFile(*output, []byte(out), 0o600); err != nil {
+		fmt.Fprintf(stderr, "conflux: error writing %s: %v\n", *output, err)
+		return 1
+	}
+
+	return 0
+}
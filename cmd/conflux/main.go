@@ -0,0 +1,106 @@
+// Command conflux validates and explains the configuration described by a manifest
+// without having to write Go.
+//
+// Usage:
+//
+//	conflux [-manifest conflux.yml] <command> [arguments]
+//
+// The commands are:
+//
+//	validate     exit non-zero and print the diagnostics if a required key is missing
+//	check        lint the config files of the manifest's readers against its keys
+//	explain KEY  show the value of KEY in every reader, and which one was used
+//	print        print the effective configuration, with secrets and credentials redacted
+//	export       write the effective configuration as dotenv, shell, yaml or json
+//	exec         run a process with the effective configuration as environment variables
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dannyvelas/conflux"
+)
+
+type command struct {
+	usage string
+	run   func(manifestPath string, args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"check":    {usage: "check", run: withManifest(runCheck)},
+	"validate": {usage: "validate", run: withManifest(runValidate)},
+	"explain":  {usage: "explain [-all] KEY", run: withManifest(runExplain)},
+	"print":    {usage: "print [-format table|yaml|json] [-reveal-last N] [-all]", run: withManifest(runPrint)},
+	"export":   {usage: "export [-format dotenv|shell|yaml|yaml-nested|json] [-prefix PREFIX] [-o FILE]", run: withManifest(runExport)},
+	"exec":     {usage: "exec [-yaml PATH]... [-env] [-bitwarden] [-vault] [-aws] [-prefix PREFIX] -- COMMAND [ARGS]...", run: runExec},
+}
+
+var commandOrder = []string{"validate", "check", "explain", "print", "export", "exec"}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("conflux", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	manifestPath := flagSet.String("manifest", "conflux.yml", "path to the manifest that describes the readers and keys")
+	flagSet.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: conflux [-manifest conflux.yml] <command> [arguments]\n\nCommands:\n")
+		for _, name := range commandOrder {
+			fmt.Fprintf(stderr, "  %s\n", commands[name].usage)
+		}
+		fmt.Fprintf(stderr, "\nFlags:\n")
+		flagSet.PrintDefaults()
+	}
+
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+
+	if flagSet.NArg() == 0 {
+		flagSet.Usage()
+		return 2
+	}
+
+	cmd, ok := commands[flagSet.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "conflux: unknown command %q\n", flagSet.Arg(0))
+		flagSet.Usage()
+		return 2
+	}
+
+	return cmd.run(*manifestPath, flagSet.Args()[1:], stdout, stderr)
+}
+
+// withManifest adapts a command that needs a manifest so that the manifest is loaded before it runs
+func withManifest(run func(m manifest, args []string, stdout, stderr io.Writer) int) func(string, []string, io.Writer, io.Writer) int {
+	return func(manifestPath string, args []string, stdout, stderr io.Writer) int {
+		m, err := loadManifest(manifestPath)
+		if err != nil {
+			printError(stderr, err)
+			return 1
+		}
+		return run(m, args, stdout, stderr)
+	}
+}
+
+// printError prints err, along with a pointer to the bad line if it is an error parsing a config file
+func printError(stderr io.Writer, err error) {
+	fmt.Fprintf(stderr, "conflux: %v\n", err)
+
+	var parseErr *conflux.FileParseError
+	if !errors.As(err, &parseErr) || parseErr.Snippet == "" {
+		return
+	}
+
+	gutter := strconv.Itoa(parseErr.Line)
+	fmt.Fprintf(stderr, "\n %s | %s\n", gutter, parseErr.Snippet)
+	fmt.Fprintf(stderr, " %s | %s^\n", strings.Repeat(" ", len(gutter)), strings.Repeat(" ", max(parseErr.Column-1, 0)))
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testManifest = `readers:
+  - type: yaml
+    paths: [config/all.yml, config/prod.yml]
+  - type: env
+keys:
+  - name: ssh_port
+    required: true
+  - name: db_password
+    required: true
+    secret: true
+`
+
+func setupManifest(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+func TestRun(t *testing.T) {
+	setupManifest(t, map[string]string{
+		"conflux.yml":     testManifest,
+		"config/all.yml":  "ssh_port: 22\ndb_password: hunter2\n",
+		"config/prod.yml": "ssh_port: 2222\n",
+	})
+	t.Setenv("SSH_PORT", "9999")
+
+	cases := []struct {
+		name         string
+		args         []string
+		expectedCode int
+		expected     []string
+		unexpected   []string
+	}{
+		{
+			name:     "validate",
+			args:     []string{"validate"},
+			expected: []string{"ssh_port", "loaded"},
+		},
+		{
+			name:     "explain",
+			args:     []string{"explain", "SSH_PORT"},
+			expected: []string{"yaml    2222", "env     9999   (used)"},
+		},
+		{
+			name:       "print",
+			args:       []string{"print", "-format", "json"},
+			expected:   []string{`"value": "9999"`, `"value": "[REDACTED]"`},
+			unexpected: []string{"hunter2"},
+		},
+		{
+			name:     "export",
+			args:     []string{"export", "-format", "shell"},
+			expected: []string{"export SSH_PORT=9999", "export DB_PASSWORD=hunter2"},
+		},
+		{
+			name:         "explain unset key",
+			args:         []string{"explain", "unset_key"},
+			expectedCode: 1,
+		},
+		{
+			name:         "unknown command",
+			args:         []string{"frobnicate"},
+			expectedCode: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr); code != tc.expectedCode {
+				t.Fatalf("expected exit code %d, got %d. stderr: %s", tc.expectedCode, code, stderr.String())
+			}
+			for _, s := range tc.expected {
+				if !strings.Contains(stdout.String(), s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, stdout.String())
+				}
+			}
+			for _, s := range tc.unexpected {
+				if strings.Contains(stdout.String(), s) {
+					t.Errorf("expected output to not contain %q, got:\n%s", s, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRun_Credentials(t *testing.T) {
+	setupManifest(t, map[string]string{
+		"conflux.yml":    "readers:\n  - type: yaml\n    paths: [config/all.yml]\n  - type: env\n  - type: bitwarden\nkeys:\n  - name: ssh_port\n",
+		"config/all.yml": "ssh_port: 22\n",
+		"nokeys.yml":     "readers:\n  - type: yaml\n    paths: [config/all.yml]\n",
+	})
+	t.Setenv("BITWARDEN_ACCESS_TOKEN", "s3cr3t-token")
+	t.Setenv("CONFLUX_CACHE_KEY", "s3cr3t-cache-key")
+
+	cases := []struct {
+		name         string
+		args         []string
+		expectedCode int
+		expected     []string
+	}{
+		{name: "print only shows the keys of the manifest", args: []string{"print"}, expected: []string{"ssh_port"}},
+		{name: "print masks credentials with -all", args: []string{"print", "-all"}, expected: []string{"bitwarden_access_token", "conflux_cache_key", "[REDACTED]"}},
+		{name: "print needs -all without keys", args: []string{"-manifest", "nokeys.yml", "print"}, expectedCode: 1},
+		{name: "explain needs -all for undeclared keys", args: []string{"explain", "bitwarden_access_token"}, expectedCode: 1},
+		{name: "explain masks credentials with -all", args: []string{"explain", "-all", "bitwarden_access_token"}, expected: []string{"[REDACTED]"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr); code != tc.expectedCode {
+				t.Fatalf("expected exit code %d, got %d. stderr: %s", tc.expectedCode, code, stderr.String())
+			}
+			for _, s := range tc.expected {
+				if !strings.Contains(stdout.String(), s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, stdout.String())
+				}
+			}
+			if output := stdout.String() + stderr.String(); strings.Contains(output, "s3cr3t") {
+				t.Errorf("expected credentials to be masked, got:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestRun_ValidateMissing(t *testing.T) {
+	setupManifest(t, map[string]string{
+		"conflux.yml":    testManifest,
+		"config/all.yml": "ssh_port: 22\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "db_password") || !strings.Contains(stdout.String(), "missing") {
+		t.Errorf("expected diagnostics to report db_password as missing, got:\n%s", stdout.String())
+	}
+}
+
+func TestRun_Exec(t *testing.T) {
+	setupManifest(t, map[string]string{
+		"conflux.yml":     testManifest,
+		"config/all.yml":  "ssh_port: 22\ndb_password: hunter2\n",
+		"config/prod.yml": "ssh_port: 2222\n",
+	})
+	t.Setenv("SSH_PORT", "9999")
+
+	cases := []struct {
+		name         string
+		args         []string
+		expectedCode int
+		expected     string
+	}{
+		{
+			name:     "manifest readers",
+			args:     []string{"exec", "--", "sh", "-c", "echo $SSH_PORT $DB_PASSWORD"},
+			expected: "9999 hunter2\n",
+		},
+		{
+			name:     "readers from flags with prefix",
+			args:     []string{"exec", "-yaml", "config/all.yml", "-prefix", "APP_", "--", "sh", "-c", "echo $APP_SSH_PORT $APP_DB_PASSWORD"},
+			expected: "22 hunter2\n",
+		},
+		{
+			name:         "refuses to run with missing keys",
+			args:         []string{"exec", "-yaml", "config/prod.yml", "--", "sh", "-c", "echo ran"},
+			expectedCode: 1,
+		},
+		{
+			name:         "exit code of the child",
+			args:         []string{"exec", "--", "sh", "-c", "exit 3"},
+			expectedCode: 3,
+		},
+		{
+			name:         "no command",
+			args:         []string{"exec"},
+			expectedCode: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, &stdout, &stderr); code != tc.expectedCode {
+				t.Fatalf("expected exit code %d, got %d. stderr: %s", tc.expectedCode, code, stderr.String())
+			}
+			if stdout.String() != tc.expected {
+				t.Errorf("expected output %q, got %q", tc.expected, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_Check(t *testing.T) {
+	setupManifest(t, map[string]string{
+		"conflux.yml":     testManifest,
+		"config/all.yml":  "ssh_port: 22\nssh_prot: 23\n",
+		"config/prod.yml": "ssh_port: 2222\n",
+	})
+	t.Setenv("DB_PASSWORD", "hunter2")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"check"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d. stderr: %s", code, stderr.String())
+	}
+
+	// env isn't a file-based reader, so db_password is missing
+	expected := "missing required key db_password\nconfig/all.yml:2:1: unknown key ssh_prot\n"
+	if stdout.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestRun_ParseError(t *testing.T) {
+	setupManifest(t, map[string]string{
+		"conflux.yml":     testManifest,
+		"config/all.yml":  "ssh_port: 22\ndb_password: [hunter2\n",
+		"config/prod.yml": "ssh_port: 2222\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+
+	expected := "\n 2 | db_password: [hunter2\n   |              ^\n"
+	if !strings.HasSuffix(stderr.String(), expected) {
+		t.Errorf("expected stderr to end with %q, got %q", expected, stderr.String())
+	}
+}
+
+func TestRun_ManifestParseError(t *testing.T) {
+	setupManifest(t, map[string]string{
+		"conflux.yml": "readers:\n  - type: [yaml\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+
+	if !strings.Contains(stderr.String(), "conflux.yml:2:") {
+		t.Errorf("expected stderr to point at the manifest, got %q", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "\n 2 |   - type: [yaml\n") {
+		t.Errorf("expected stderr to show the bad line of the manifest, got %q", stderr.String())
+	}
+}
+
+func TestRun_ValidateFailedReaders(t *testing.T) {
+	setupManifest(t, map[string]string{
+		"conflux.yml": `readers:
+  - type: yaml
+    paths: [config/all.yml]
+  - type: http
+    url: http://127.0.0.1:1/a
+  - type: http
+    url: http://127.0.0.1:1/b
+`,
+		"config/all.yml": "ssh_port: 22\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	for _, url := range []string{"http://127.0.0.1:1/a", "http://127.0.0.1:1/b"} {
+		if !strings.Contains(stdout.String(), url) || !strings.Contains(stderr.String(), url) {
+			t.Errorf("expected both the diagnostics and the error to report %s, got:\n%s\n%s", url, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestRun_ValidateNotConfigured(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		required     string
+		expectedCode int
+		expected     string
+	}{
+		{name: "optional", expectedCode: 0, expected: "Skipped: needs bitwarden_access_token"},
+		{name: "required", required: "    required: true\n", expectedCode: 1, expected: "reader not configured: needs bitwarden_access_token"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setupManifest(t, map[string]string{
+				"conflux.yml":    "readers:\n  - type: yaml\n    paths: [config/all.yml]\n  - type: bitwarden\n" + tc.required,
+				"config/all.yml": "ssh_port: 22\n",
+			})
+
+			var stdout, stderr bytes.Buffer
+			if code := run([]string{"validate"}, &stdout, &stderr); code != tc.expectedCode {
+				t.Fatalf("expected exit code %d, got %d:\n%s", tc.expectedCode, code, stderr.String())
+			}
+			if output := stdout.String() + stderr.String(); !strings.Contains(output, tc.expected) {
+				t.Errorf("expected output to contain %q, got:\n%s", tc.expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dannyvelas/conflux"
+	"github.com/goccy/go-yaml"
+)
+
+// manifest describes the readers to read config from, and the keys that are expected
+//
+//	readers:
+//	  - type: yaml
+//	    paths: [config/all.yml, config/prod.yml]
+//	  - type: env
+//	  - type: bitwarden
+//	keys:
+//	  - name: ssh_port
+//	    required: true
+//	  - name: db_password
+//	    required: true
+//	    secret: true
+type manifest struct {
+	Readers []readerSpec `yaml:"readers"`
+	Keys    []keySpec    `yaml:"keys"`
+}
+
+type readerSpec struct {
+	// Type is one of yaml, env, bitwarden, vault, aws or http
+	Type string `yaml:"type"`
+	// Paths are the files or directories of a yaml reader, from lowest to highest priority
+	Paths []string `yaml:"paths"`
+	// URL is the url of an http reader
+	URL string `yaml:"url"`
+	// BearerTokenKey is the config key of the bearer token of an http reader
+	BearerTokenKey string `yaml:"bearer_token_key"`
+	// KeyPrefix is the key prefix of a bitwarden reader
+	KeyPrefix string `yaml:"key_prefix"`
+	// ProjectIDs are the project IDs of a bitwarden reader
+	ProjectIDs []string `yaml:"project_ids"`
+	// Required makes a bitwarden, vault or aws reader fail when it is not configured, instead of being skipped
+	Required bool `yaml:"required"`
+}
+
+type keySpec struct {
+	Name     string `yaml:"name"`
+	Required bool   `yaml:"required"`
+	Secret   bool   `yaml:"secret"`
+}
+
+func loadManifest(path string) (manifest, error) {
+	m, err := parseManifest(path)
+	if err != nil {
+		return manifest{}, err
+	}
+
+	if len(m.Readers) == 0 {
+		return manifest{}, fmt.Errorf("manifest (%s) has no readers", path)
+	}
+
+	return m, nil
+}
+
+// parseManifest parses the manifest at path without checking that it has readers
+func parseManifest(path string) (manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return manifest{}, fmt.Errorf("error reading manifest (%s): %w", path, err)
+	}
+
+	var m manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return manifest{}, fmt.Errorf("error unmarshalling manifest: %w", conflux.NewFileParseError(path, data, err))
+	}
+
+	return m, nil
+}
+
+// newConfigMux creates a config mux with the readers of the manifest, and any other options
+func (m manifest) newConfigMux(muxOpts ...func(*conflux.ConfigMux)) (*conflux.ConfigMux, error) {
+	opts := make([]func(*conflux.ConfigMux), 0, len(m.Readers)+len(muxOpts))
+	for i, spec := range m.Readers {
+		opt, err := spec.option()
+		if err != nil {
+			return nil, fmt.Errorf("error in reader %d: %w", i, err)
+		}
+		if spec.Required {
+			opt = conflux.WithMandatoryReader(opt)
+		}
+		opts = append(opts, opt)
+	}
+	return conflux.NewConfigMux(append(opts, muxOpts...)...), nil
+}
+
+func (s readerSpec) option() (func(*conflux.ConfigMux), error) {
+	switch s.Type {
+	case "yaml":
+		if len(s.Paths) == 0 {
+			return nil, fmt.Errorf("yaml reader needs at least one path")
+		}
+		return conflux.WithYAMLFileReader(s.Paths[0], mapSlice(s.Paths[1:], conflux.WithPath)...), nil
+	case "env":
+		return conflux.WithEnvReader(), nil
+	case "bitwarden":
+		return conflux.WithBitwardenSecretReader(
+			conflux.WithBitwardenKeyPrefix(s.KeyPrefix),
+			conflux.WithBitwardenProjectIDs(s.ProjectIDs...),
+		), nil
+	case "vault":
+		return conflux.WithVaultReader(), nil
+	case "aws":
+		return conflux.WithAWSReader(), nil
+	case "http":
+		if s.URL == "" {
+			return nil, fmt.Errorf("http reader needs a url")
+		} else if s.BearerTokenKey == "" {
+			return conflux.WithHTTPReader(s.URL), nil
+		}
+		return conflux.WithHTTPReader(s.URL, conflux.WithBearerTokenFromConfig(s.BearerTokenKey)), nil
+	default:
+		return nil, fmt.Errorf("unknown reader type %q", s.Type)
+	}
+}
+
+// mapSlice applies fn to every element of s
+// this lets us build slices of reader options without naming their unexported types
+func mapSlice[S, T any](s []S, fn func(S) T) []T {
+	out := make([]T, 0, len(s))
+	for _, v := range s {
+		out = append(out, fn(v))
+	}
+	return out
+}
+
+// read reads the config of the manifest's readers into a map, along with the result that has the source of each value
+// the returned diagnostics include the status of every key of the manifest.
+// with conflux.WithContinueOnError, the partial config and diagnostics are returned along with the error
+func (m manifest) read(muxOpts ...func(*conflux.ConfigMux)) (conflux.SourcedReadResult, map[string]string, map[string]string, error) {
+	configMux, err := m.newConfigMux(muxOpts...)
+	if err != nil {
+		return conflux.SourcedReadResult{}, nil, nil, err
+	}
+
+	result, readErr := configMux.ReadSourced()
+	if readErr != nil && result.GetConfigMap() == nil {
+		return conflux.SourcedReadResult{}, nil, nil, fmt.Errorf("error reading config: %w", readErr)
+	} else if readErr != nil {
+		readErr = fmt.Errorf("error reading config: %w", readErr)
+	}
+
+	configMap := make(map[string]string)
+	diagnostics, err := conflux.Unmarshal(result, &configMap)
+	if err != nil {
+		return conflux.SourcedReadResult{}, nil, nil, fmt.Errorf("error reading config: %w", err)
+	}
+	if diagnostics == nil {
+		diagnostics = make(map[string]string)
+	}
+
+	for _, key := range m.Keys {
+		if configMap[strings.ToLower(key.Name)] != "" {
+			diagnostics[key.Name] = conflux.StatusLoaded
+		} else if key.Required {
+			diagnostics[key.Name] = conflux.StatusMissing
+		}
+	}
+
+	return result, configMap, diagnostics, readErr
+}
+
+// missingKeys returns the required keys of the manifest that don't have a value in configMap
+func (m manifest) missingKeys(configMap map[string]string) []string {
+	var missing []string
+	for _, key := range m.Keys {
+		if key.Required && configMap[strings.ToLower(key.Name)] == "" {
+			missing = append(missing, key.Name)
+		}
+	}
+	return missing
+}
+
+// secretKeys returns the keys of the manifest that are secret, along with the keys that its readers
+// read their credentials from, like bitwarden_access_token, so that credentials are never printed
+func (m manifest) secretKeys() []string {
+	var secretKeys []string
+	for _, key := range m.Keys {
+		if key.Secret {
+			secretKeys = append(secretKeys, key.Name)
+		}
+	}
+	if configMux, err := m.newConfigMux(); err == nil {
+		secretKeys = append(secretKeys, configMux.CredentialKeys()...)
+	}
+	return secretKeys
+}
+
+// hasKey reports whether name is one of the keys of the manifest
+func (m manifest) hasKey(name string) bool {
+	return slices.ContainsFunc(m.Keys, func(k keySpec) bool { return strings.EqualFold(k.Name, name) })
+}
+
+func (m manifest) isSecretKey(name string) bool {
+	return slices.ContainsFunc(m.secretKeys(), func(k string) bool { return strings.EqualFold(k, name) })
+}
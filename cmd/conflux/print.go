@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dannyvelas/conflux"
+)
+
+func runPrint(m manifest, args []string, stdout, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("print", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	format := flagSet.String("format", "table", "output format: table, yaml or json")
+	revealLast := flagSet.Int("reveal-last", 0, "number of trailing characters of secrets to show")
+	all := flagSet.Bool("all", false, "print every key that was read, instead of only the keys of the manifest")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+
+	if len(m.Keys) == 0 && !*all {
+		fmt.Fprintf(stderr, "conflux: the manifest has no keys, pass -all to print every key that was read\n")
+		return 1
+	}
+
+	result, configMap, diagnostics, err := m.read()
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	// only print the keys of the manifest so that the whole environment isn't printed
+	if !*all {
+		manifestMap := make(map[string]string, len(m.Keys))
+		for _, key := range m.Keys {
+			manifestMap[key.Name] = configMap[strings.ToLower(key.Name)]
+		}
+		configMap = manifestMap
+	}
+
+	out, err := conflux.DumpMap(configMap, conflux.DumpOptions{
+		Format:      conflux.DumpFormat(*format),
+		Sources:     result.Sources(),
+		SecretKeys:  m.secretKeys(),
+		Diagnostics: diagnostics,
+		RevealLast:  *revealLast,
+	})
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	fmt.Fprint(stdout, out)
+	return 0
+}
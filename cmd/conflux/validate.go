@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dannyvelas/conflux"
+)
+
+func runValidate(m manifest, args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintf(stderr, "Usage: conflux validate\n")
+		return 2
+	}
+
+	// keep reading when a reader fails, so that every broken source is reported at once
+	result, configMap, diagnostics, err := m.read(conflux.WithContinueOnError())
+	if err != nil && diagnostics == nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	renderer := conflux.TableRenderer{Color: isTerminal(stdout), Sources: result.DiagnosticSources()}
+	fmt.Fprint(stdout, renderer.Render(diagnostics))
+
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	if missing := m.missingKeys(configMap); len(missing) > 0 {
+		fmt.Fprintf(stderr, "conflux: %v: %v\n", conflux.ErrInvalidFields, missing)
+		return 1
+	}
+
+	return 0
+}
+
+// isTerminal reports whether w is a terminal, in which case output can be colored
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -4,11 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 )
 
 const (
 	StatusMissing = "missing"
 	StatusLoaded  = "loaded"
+	StatusInvalid = "invalid"
+	// StatusFailed is the start of the status of a reader that failed, see WithContinueOnError
+	StatusFailed = "Failed"
+	// StatusSkipped is the start of the status of a source that wasn't read, like a file that doesn't exist
+	// or a reader whose inputs are missing, see WithCustomDependentReader
+	StatusSkipped = "Skipped"
 )
 
 type validatable interface {
@@ -17,21 +25,40 @@ type validatable interface {
 	Validate(map[string]string) bool
 }
 
+type nameMapped interface {
+	// fieldNameMapper returns the NameMapper that untagged struct fields are matched with
+	fieldNameMapper() NameMapper
+}
+
+type fieldMapped interface {
+	// setFieldNameMapper sets the NameMapper of the mux that the reader was added to
+	// readers that derive their keys from a struct match untagged fields with it, and readers
+	// that look up fixed keys in the config of the mux, like the cache key, convert them with it
+	setFieldNameMapper(mapper NameMapper)
+}
+
 type fillable interface {
 	// FillInKeys takes the keys that are required and uses them to fill out remaining config fields
 	FillInKeys() error
 }
 
-func validateStruct(v any) (map[string]string, error) {
+func validateStruct(v any, mapper NameMapper) (map[string]string, error) {
 	diagnostics := make(map[string]string)
 	valid := true
 
-	tagToFieldMap, err := getTagToFieldMap(v, "conflux", "json")
+	fields, err := getMappedFields(v, mapper)
 	if err != nil {
-		return nil, fmt.Errorf("error getting tag to field map: %v", err)
+		return nil, fmt.Errorf("error getting tagged fields: %w", err)
 	}
 
-	for tag, field := range tagToFieldMap {
+	for _, field := range fields {
+		tag := field.tag
+		if enum := enumValues(field.Type); enum != nil && isStringField(field.Type) && !field.Value.IsZero() && !slices.Contains(enum, fieldString(field.Value)) {
+			diagnostics[tag] = fmt.Sprintf("%s: must be one of %s", StatusInvalid, strings.Join(enum, ", "))
+			valid = false
+			continue
+		}
+
 		if _, ok := field.Type.Tag.Lookup("required"); !ok {
 			continue
 		}
@@ -56,6 +83,9 @@ func validateStruct(v any) (map[string]string, error) {
 }
 
 // Unmarshal reads key-value pairs from the provided Reader and unmarshals them into the target struct or map.
+// Struct fields tagged with `required:"true"` are reported as missing if they are empty, and string fields
+// tagged with `enum:"a,b,c"` are reported as invalid if they have a value that isn't in the list.
+// Either way, the error matches ErrInvalidFields
 func Unmarshal(r Reader, target any) (map[string]string, error) {
 	val := reflect.ValueOf(target)
 	if val.Kind() != reflect.Pointer {
@@ -63,37 +93,53 @@ func Unmarshal(r Reader, target any) (map[string]string, error) {
 	}
 
 	readResult, err := r.Read()
-	if err != nil && !errors.Is(err, ErrInvalidFields) {
-		return nil, fmt.Errorf("error reading: %v", err)
+	if err != nil && !errors.Is(err, ErrInvalidFields) && readResult == nil {
+		return nil, fmt.Errorf("error reading: %w", err)
 	}
 	// if errors.Is(err, ErrInvalidFields) we want to continue
 	// because its possible that after helfromMap, the
 	// resulting target will have all required fields regardless
+	// readers that return a partial result along with an error, like a ConfigMux
+	// with WithContinueOnError, also fill the target, but their error is returned
+	var readErr error
+	if err != nil && !errors.Is(err, ErrInvalidFields) {
+		readErr = fmt.Errorf("error reading: %w", err)
+	}
 
-	if err := fromMap(readResult.GetConfigMap(), target); err != nil {
-		return nil, fmt.Errorf("error converting map into target: %v", err)
+	// readers like a ConfigMux with WithNameMapper also decide which keys untagged fields match
+	var mapper NameMapper
+	if r, ok := r.(nameMapped); ok {
+		mapper = r.fieldNameMapper()
+	}
+
+	if err := fromMap(readResult.GetConfigMap(), target, mapper); err != nil {
+		return nil, fmt.Errorf("error converting map into target: %w", err)
 	}
 
 	readDiagnostics := getDiagnostics(readResult)
 
 	val = val.Elem()
 	if val.Kind() == reflect.Map {
-		return readDiagnostics, nil
+		return readDiagnostics, readErr
 	}
 
-	targetDiagnostics, err := validateStruct(target)
+	targetDiagnostics, err := validateStruct(target, mapper)
 	if err != nil && !errors.Is(err, ErrInvalidFields) {
-		return nil, fmt.Errorf("error unmarhsalling into config: %v", err)
+		return nil, fmt.Errorf("error unmarhsalling into config: %w", err)
 	}
 
 	mergedDiagnostics := mergeMaps(readDiagnostics, targetDiagnostics)
-	if errors.Is(err, ErrInvalidFields) {
+	if errors.Is(err, ErrInvalidFields) && readErr != nil {
+		return mergedDiagnostics, errors.Join(readErr, ErrInvalidFields)
+	} else if errors.Is(err, ErrInvalidFields) {
 		return mergedDiagnostics, ErrInvalidFields
+	} else if readErr != nil {
+		return mergedDiagnostics, readErr
 	}
 
 	if fillableTarget, ok := target.(fillable); ok {
 		if err := fillableTarget.FillInKeys(); err != nil {
-			return nil, fmt.Errorf("error filling in fields: %v", err)
+			return nil, fmt.Errorf("error filling in fields: %w", err)
 		}
 	}
 
@@ -101,7 +147,10 @@ func Unmarshal(r Reader, target any) (map[string]string, error) {
 }
 
 func getDiagnostics(r ReadResult) map[string]string {
-	if v, ok := r.(DiagnosticReadResult); ok {
+	switch v := r.(type) {
+	case DiagnosticReadResult:
+		return v.diagnostics
+	case SourcedReadResult:
 		return v.diagnostics
 	}
 	return nil
@@ -1,14 +1,100 @@
 package conflux
 
 import (
+	"errors"
 	"fmt"
 	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 )
 
 var _ Reader = (*ConfigMux)(nil)
 
 type ConfigMux struct {
-	readerFns []func(configMap map[string]string) Reader
+	readers         []muxReader
+	continueOnError bool
+	nameMapper      NameMapper
+}
+
+// muxReader is a reader that was added to the mux, along with the source it reads from
+type muxReader struct {
+	source Source
+	// lazy is true if the reader needs the config that was read by the readers before it
+	// readers that aren't lazy are read concurrently
+	lazy bool
+	// inputs are the config keys that a lazy reader reads from, if it declared them.
+	// a reader with inputs only gets those keys, and is read concurrently with the readers with inputs next to it
+	inputs []string
+	// needs are the inputs without which the reader is not configured, so it is skipped
+	// each need is a list of alternative keys, any of which is enough
+	needs [][]string
+	// mandatory is true if a reader that is not configured is an error instead of being skipped
+	mandatory bool
+	// nameMapper converts the keys of the reader, instead of the NameMapper of the mux
+	nameMapper NameMapper
+	newReader  func(configMap map[string]string) Reader
+}
+
+// inputsFrom returns the config values of the inputs of the reader
+// the inputs keep the names that the reader declared, even if the keys of configMap were converted by mapper
+func (r muxReader) inputsFrom(configMap map[string]string, mapper NameMapper) map[string]string {
+	inputs := make(map[string]string, len(r.inputs))
+	for _, key := range r.inputs {
+		mappedKey := key
+		if mapper != nil {
+			mappedKey = strings.ToLower(mapper(key))
+		}
+		if value, ok := configMap[mappedKey]; ok {
+			inputs[key] = value
+		}
+	}
+	return inputs
+}
+
+// isDependent returns true if the reader is lazy and declared its inputs
+func (r muxReader) isDependent() bool {
+	return r.lazy && r.inputs != nil
+}
+
+// missingNeeds returns the needs of the reader that are missing or empty in inputs
+// a need with alternatives is returned like "bitwarden_access_token or bws_access_token"
+func (r muxReader) missingNeeds(inputs map[string]string) []string {
+	var missing []string
+	for _, alternatives := range r.needs {
+		if !slices.ContainsFunc(alternatives, func(key string) bool { return inputs[key] != "" }) {
+			missing = append(missing, strings.Join(alternatives, " or "))
+		}
+	}
+	return missing
+}
+
+// muxResult is the result of reading from one of the readers of the mux
+type muxResult struct {
+	configMap   map[string]string
+	diagnostics map[string]string
+	err         error
+}
+
+// dependentRead is the read of a reader with inputs that was started before the readers in front of it were merged
+type dependentRead struct {
+	inputs map[string]string
+	result chan muxResult
+}
+
+// Source describes the reader that a config value came from
+type Source struct {
+	// Reader is the name of the reader, like "yaml", "env" or "bitwarden"
+	Reader string
+	// Secret is true if the reader reads from a secret manager
+	Secret bool
+}
+
+// SourcedValue is a value that a reader had for a config key
+type SourcedValue struct {
+	Source Source
+	Value  string
 }
 
 // NewConfigMux creates a new config mux which can read from multiple readers
@@ -22,53 +108,424 @@ func NewConfigMux(opts ...func(*ConfigMux)) *ConfigMux {
 	return &configMux
 }
 
+// Read reads from every reader of the mux and merges their config, in the order the readers were added.
+// The result is a SourcedReadResult
 func (r *ConfigMux) Read() (ReadResult, error) {
+	result, err := r.ReadSourced()
+	if result.configMap == nil {
+		return nil, err
+	}
+	return result, err
+}
+
+// ReadSourced is like Read, but it returns the SourcedReadResult, which has the source of every value.
+// With WithContinueOnError, the partial result is returned along with the error.
+// Pass the result to Unmarshal to fill a struct with it, for example:
+//
+//	result, err := configMux.ReadSourced()
+//	...
+//	diagnostics, err := Unmarshal(result, &cfg)
+//	...
+//	out, err := Dump(&cfg, DumpOptions{Sources: result.Sources(), Diagnostics: diagnostics})
+func (r *ConfigMux) ReadSourced() (SourcedReadResult, error) {
 	configMap, allDiagnostics := make(map[string]string), make(map[string]string)
-	for _, readerFn := range r.readerFns {
-		reader := readerFn(configMap)
-		readerDiagnostics, err := Unmarshal(reader, &configMap)
+	sources, diagnosticSources := make(map[string]Source), make(map[string]string)
+	history := make(map[string][]SourcedValue)
+
+	// readers that don't depend on the config of other readers are read concurrently,
+	// but their results are still merged in priority order below
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	pending := make([]chan muxResult, len(r.readers))
+	for i, muxReader := range r.readers {
+		if muxReader.lazy {
+			continue
+		}
+		pending[i] = make(chan muxResult, 1)
+		wg.Go(func() {
+			pending[i] <- readMuxReader(r.newReader(muxReader, nil))
+		})
+	}
+
+	var errs []error
+	started := make([]*dependentRead, len(r.readers))
+	for i, muxReader := range r.readers {
+		var result muxResult
+		switch {
+		case pending[i] != nil:
+			result = <-pending[i]
+		case muxReader.inputs == nil:
+			// lazy readers are read one at a time, so that each one sees the config of every reader before it
+			result = readMuxReader(r.newReader(muxReader, configMap))
+		default:
+			if started[i] == nil {
+				r.startDependentReaders(&wg, i, configMap, started, r.isFirstDependent(i))
+			}
+
+			inputs := muxReader.inputsFrom(configMap, r.nameMapper)
+			missing := muxReader.missingNeeds(inputs)
+			if len(missing) > 0 && !muxReader.mandatory {
+				// a reader that isn't configured gets a single diagnostic, instead of one for each of its missing keys
+				allDiagnostics[readerID(i, muxReader.source)] = fmt.Sprintf("%s: needs %s", StatusSkipped, strings.Join(missing, ", "))
+				diagnosticSources[readerID(i, muxReader.source)] = muxReader.source.Reader
+				continue
+			}
+
+			if len(missing) > 0 {
+				result = muxResult{err: fmt.Errorf("%w: needs %s", ErrNotConfigured, strings.Join(missing, ", "))}
+			} else if started[i] != nil && maps.Equal(started[i].inputs, inputs) {
+				result = <-started[i].result
+			} else {
+				// a reader in front of it changed one of its inputs, so it is read again
+				result = readMuxReader(r.newReader(muxReader, inputs))
+			}
+		}
+
+		readerMap, readerDiagnostics, err := result.configMap, result.diagnostics, result.err
 		if err != nil {
-			return nil, fmt.Errorf("error unmarshalling from reader to map: %v", err)
+			// readers that know where they were reading from return their own ReaderError
+			var readerErr *ReaderError
+			if !errors.As(err, &readerErr) {
+				readerErr = &ReaderError{Reader: muxReader.source.Reader, Err: err}
+				err = readerErr
+			}
+			if !r.continueOnError {
+				return SourcedReadResult{}, fmt.Errorf("error unmarshalling from reader to map: %w", err)
+			}
+
+			status := fmt.Sprintf("%s: %v", StatusFailed, readerErr.Err)
+			if readerErr.Source != "" {
+				status = fmt.Sprintf("%s (%s): %v", StatusFailed, readerErr.Source, readerErr.Err)
+			}
+			allDiagnostics[readerID(i, muxReader.source)] = status
+			diagnosticSources[readerID(i, muxReader.source)] = muxReader.source.Reader
+			errs = append(errs, err)
+		}
+		// keys are normalized so that a key like SSH_PORT from a higher priority
+		// reader overrides ssh_port from a lower priority one
+		mapper := r.readerNameMapper(muxReader)
+		for k, v := range readerMap {
+			if mapper != nil {
+				k = mapper(k)
+			}
+			configMap[strings.ToLower(k)] = v
+			sources[strings.ToLower(k)] = muxReader.source
+			history[strings.ToLower(k)] = append(history[strings.ToLower(k)], SourcedValue{muxReader.source, v})
+		}
+		for k, v := range readerDiagnostics {
+			// a diagnostic that a reader reports under its own name, like "bitwarden", is about the reader, not a config key
+			if k == muxReader.source.Reader {
+				k = readerID(i, muxReader.source)
+			}
+			allDiagnostics[k] = v
+			diagnosticSources[k] = muxReader.source.Reader
 		}
-		maps.Copy(allDiagnostics, readerDiagnostics)
 	}
 
-	return NewDiagnosticReadResult(configMap, allDiagnostics), nil
+	// the partial config is returned along with the errors, so that Unmarshal can still report on it
+	result := SourcedReadResult{
+		configMap:         configMap,
+		diagnostics:       allDiagnostics,
+		sources:           sources,
+		diagnosticSources: diagnosticSources,
+		history:           history,
+		nameMapper:        r.nameMapper,
+	}
+	return result, errors.Join(errs...)
+}
+
+// CredentialKeys returns the config keys that the readers of the mux read their own configuration from,
+// like bitwarden_access_token, vault_token or the key of a bearer token, along with the key of the cache.
+// They are converted with the NameMapper of the mux, like the keys of the config.
+// Pass them to DumpOptions.SecretKeys so that credentials are masked like secrets
+func (r *ConfigMux) CredentialKeys() []string {
+	keys := []string{defaultCacheKeyConfigKey}
+	for _, muxReader := range r.readers {
+		keys = append(keys, muxReader.inputs...)
+	}
+
+	for i, key := range keys {
+		if r.nameMapper != nil {
+			key = r.nameMapper(key)
+		}
+		keys[i] = strings.ToLower(key)
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// readerID returns the key of the diagnostics about the reader at index i, like "custom #2"
+// the position of the reader makes it unique, even if there are other readers of the same kind or a config key with its name
+func readerID(i int, source Source) string {
+	return fmt.Sprintf("%s #%d", source.Reader, i+1)
+}
+
+// readerNameMapper returns the NameMapper that the keys of muxReader are converted with, if any
+func (r *ConfigMux) readerNameMapper(muxReader muxReader) NameMapper {
+	if muxReader.nameMapper != nil {
+		return muxReader.nameMapper
+	}
+	return r.nameMapper
+}
+
+func (r *ConfigMux) fieldNameMapper() NameMapper {
+	return r.nameMapper
+}
+
+// newReader creates the reader of muxReader with configMap, and hands it the NameMapper of the mux
+// so that readers that derive their keys from a struct, like the flag reader, match its fields like Unmarshal does
+func (r *ConfigMux) newReader(muxReader muxReader, configMap map[string]string) Reader {
+	reader := muxReader.newReader(configMap)
+	if mapped, ok := reader.(fieldMapped); ok && r.nameMapper != nil {
+		mapped.setFieldNameMapper(r.nameMapper)
+	}
+	return reader
+}
+
+// isFirstDependent returns true if the reader at index i has inputs, and the reader before it doesn't
+func (r *ConfigMux) isFirstDependent(i int) bool {
+	return r.readers[i].isDependent() && (i == 0 || !r.readers[i-1].isDependent())
+}
+
+// startDependentReaders starts reading the reader at index i with the inputs that it has in configMap.
+// If all is true, every reader with inputs right after it is started too.
+// Readers that were already started are left running
+func (r *ConfigMux) startDependentReaders(wg *sync.WaitGroup, i int, configMap map[string]string, started []*dependentRead, all bool) {
+	for j := i; j < len(r.readers) && r.readers[j].isDependent() && (all || j == i); j++ {
+		if started[j] != nil {
+			continue
+		}
+
+		muxReader := r.readers[j]
+		inputs := muxReader.inputsFrom(configMap, r.nameMapper)
+		if len(muxReader.missingNeeds(inputs)) > 0 {
+			continue
+		}
+
+		read := &dependentRead{inputs: inputs, result: make(chan muxResult, 1)}
+		started[j] = read
+		wg.Go(func() {
+			read.result <- readMuxReader(r.newReader(muxReader, inputs))
+		})
+	}
+}
+
+func readMuxReader(reader Reader) muxResult {
+	readerMap := make(map[string]string)
+	readerDiagnostics, err := Unmarshal(reader, &readerMap)
+	return muxResult{configMap: readerMap, diagnostics: readerDiagnostics, err: err}
+}
+
+// addReader adds a reader that needs the config that was read by the readers before it
+func (r *ConfigMux) addReader(source Source, newReader func(configMap map[string]string) Reader) {
+	r.readers = append(r.readers, muxReader{source: source, lazy: true, newReader: newReader})
+}
+
+// addDependentReader adds a reader that only needs the given config keys
+// it is skipped if any of its needs is missing
+func (r *ConfigMux) addDependentReader(source Source, inputs []string, needs [][]string, newReader func(configMap map[string]string) Reader) {
+	lowerNeeds := make([][]string, len(needs))
+	for i, alternatives := range needs {
+		lowerNeeds[i] = lowerKeys(alternatives)
+	}
+	r.readers = append(r.readers, muxReader{source: source, lazy: true, inputs: lowerKeys(inputs), needs: lowerNeeds, newReader: newReader})
+}
+
+// readerConfigKeys returns the config keys that a reader reads its own configuration from
+func readerConfigKeys(reader string) []string {
+	return readerKeys(reader, false)
+}
+
+// readerNeeds returns the config keys without which a reader is not configured
+// each need is a list of alternative keys, any of which is enough
+func readerNeeds(reader string) [][]string {
+	var needs [][]string
+	if reader == "bitwarden" {
+		// the access token can also be set with the name used by the official Bitwarden tooling
+		needs = append(needs, []string{"bitwarden_access_token", "bws_access_token"})
+	}
+	for _, key := range readerKeys(reader, true) {
+		needs = append(needs, []string{key})
+	}
+	return needs
+}
+
+func readerKeys(reader string, onlyRequired bool) []string {
+	var config any
+	switch reader {
+	case "bitwarden":
+		config = bitwardenConfig{}
+	case "vault":
+		config = vaultConfig{}
+	case "aws":
+		config = awsConfig{}
+	default:
+		return nil
+	}
+
+	tagToFieldMap, _ := getTagToFieldMap(config, "conflux", "json")
+	keys := make([]string, 0, len(tagToFieldMap))
+	for tag, field := range tagToFieldMap {
+		if _, required := field.Type.Tag.Lookup("required"); required || !onlyRequired {
+			keys = append(keys, tag)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// addIndependentReader adds a reader that doesn't depend on the config of other readers,
+// so that it can be read concurrently with them
+func (r *ConfigMux) addIndependentReader(source Source, newReader func() Reader) {
+	r.readers = append(r.readers, muxReader{source: source, newReader: func(_ map[string]string) Reader { return newReader() }})
+}
+
+// WithContinueOnError makes the config mux keep reading from the rest of its readers when one of them fails.
+// Read returns the config of the readers that succeeded, along with every error joined with errors.Join,
+// and each reader that failed gets a diagnostic with a status that starts with "Failed".
+// Diagnostics about a reader are keyed by its name and position, like "custom #2", so they don't clash with config keys.
+// Unmarshal fills its target with the partial config and returns its diagnostics along with the error,
+// so that a single report shows every broken source
+func WithContinueOnError() func(*ConfigMux) {
+	return func(configMux *ConfigMux) {
+		configMux.continueOnError = true
+	}
 }
 
 // WithYAMLFileReader adds a yaml file reader to the config mux
 func WithYAMLFileReader(path string, opts ...func(*yamlFileReader)) func(*ConfigMux) {
 	return func(configMux *ConfigMux) {
-		configMux.readerFns = append(
-			configMux.readerFns,
-			func(_ map[string]string) Reader {
-				return NewYAMLFileReader(path, opts...)
-			},
-		)
+		configMux.addIndependentReader(Source{Reader: "yaml"}, func() Reader {
+			return NewYAMLFileReader(path, opts...)
+		})
 	}
 }
 
 // WithEnvReader adds an environment variable reader to the config mux
 func WithEnvReader(opts ...func(*envReader)) func(*ConfigMux) {
 	return func(configMux *ConfigMux) {
-		configMux.readerFns = append(
-			configMux.readerFns,
-			func(_ map[string]string) Reader {
-				return NewEnvReader(opts...)
-			},
-		)
+		configMux.addIndependentReader(Source{Reader: "env"}, func() Reader {
+			return NewEnvReader(opts...)
+		})
+	}
+}
+
+// WithFlagReader adds a command-line flag reader to the config mux
+// Use the WithFlagTarget option to tell the reader which struct to derive flags from.
+// Flags usually have the highest priority, so this should be the last reader passed in
+func WithFlagReader(args []string, opts ...func(*flagReader)) func(*ConfigMux) {
+	return func(configMux *ConfigMux) {
+		configMux.addIndependentReader(Source{Reader: "flags"}, func() Reader {
+			return NewFlagReader(args, opts...)
+		})
+	}
+}
+
+// WithHTTPReader adds a reader of a remote JSON document to the config mux
+// Headers set with WithHeaderFromConfig and WithBearerTokenFromConfig are read
+// from the config values that were read by previous readers
+func WithHTTPReader(url string, opts ...func(*httpReader)) func(*ConfigMux) {
+	reader := NewHTTPReader(url, opts...)
+	return func(configMux *ConfigMux) {
+		newReader := func(configMap map[string]string) Reader {
+			// copy the reader so that its ETag cache is shared between reads
+			r := *reader
+			r.configMap = configMap
+			return &r
+		}
+
+		if len(reader.headerKeys) == 0 {
+			configMux.addIndependentReader(Source{Reader: "http"}, func() Reader { return newReader(nil) })
+			return
+		}
+
+		inputs := make([]string, 0, len(reader.headerKeys))
+		for _, header := range reader.headerKeys {
+			inputs = append(inputs, header.key)
+		}
+		slices.Sort(inputs)
+		configMux.addDependentReader(Source{Reader: "http"}, inputs, nil, newReader)
 	}
 }
 
 // WithBitwardenSecretReader adds a Bitwarden secret reader to the config mux
-func WithBitwardenSecretReader() func(*ConfigMux) {
+func WithBitwardenSecretReader(opts ...func(*bitwardenSecretReader)) func(*ConfigMux) {
 	return func(configMux *ConfigMux) {
-		configMux.readerFns = append(configMux.readerFns, func(configMap map[string]string) Reader {
-			return NewBitwardenSecretReader(configMap)
+		configMux.addDependentReader(Source{Reader: "bitwarden", Secret: true}, readerConfigKeys("bitwarden"), readerNeeds("bitwarden"), func(configMap map[string]string) Reader {
+			return NewBitwardenSecretReader(configMap, opts...)
 		})
 	}
 }
 
+// WithVaultReader adds a HashiCorp Vault KV secret reader to the config mux
+// It authenticates to Vault with the vault_* config values that were read by previous readers
+func WithVaultReader() func(*ConfigMux) {
+	return func(configMux *ConfigMux) {
+		configMux.addDependentReader(Source{Reader: "vault", Secret: true}, readerConfigKeys("vault"), readerNeeds("vault"), func(configMap map[string]string) Reader {
+			return NewVaultSecretReader(configMap)
+		})
+	}
+}
+
+// WithAWSReader adds an AWS SSM Parameter Store and Secrets Manager reader to the config mux
+// It authenticates to AWS with the aws_* config values that were read by previous readers
+func WithAWSReader() func(*ConfigMux) {
+	return func(configMux *ConfigMux) {
+		configMux.addDependentReader(Source{Reader: "aws", Secret: true}, readerConfigKeys("aws"), readerNeeds("aws"), func(configMap map[string]string) Reader {
+			return NewAWSSecretReader(configMap)
+		})
+	}
+}
+
+// WithCache wraps the reader added by readerOpt with an encrypted cache stored at path.
+// For example, WithCache(".cache/bitwarden", WithBitwardenSecretReader(), WithCacheTTL(time.Hour))
+// See NewCachedReader for how the cache behaves.
+// By default, the cache is encrypted with the value of the conflux_cache_key config key,
+// so it must be read by a previous reader. If readerOpt adds more than one reader,
+// each one gets its own cache file with an index appended to path.
+// A reader that declares the keys it needs, like the Bitwarden reader, is still skipped when it is not configured
+func WithCache(path string, readerOpt func(*ConfigMux), opts ...func(*cachedReader)) func(*ConfigMux) {
+	return func(configMux *ConfigMux) {
+		inner := ConfigMux{}
+		readerOpt(&inner)
+
+		for i, muxReader := range inner.readers {
+			cachePath := path
+			if len(inner.readers) > 1 {
+				cachePath = fmt.Sprintf("%s.%d", path, i)
+			}
+
+			// the cached reader keeps the inputs, needs and mandatory flag of the reader that it wraps,
+			// and the cache key is read from config, so cached readers are always lazy
+			cached := muxReader
+			cached.lazy = true
+			if probe := NewCachedReader(nil, cachePath, opts...); muxReader.isDependent() && probe.key == "" {
+				keyConfigKey := strings.ToLower(probe.keyConfigKey)
+				if !slices.Contains(cached.inputs, keyConfigKey) {
+					cached.inputs = append(slices.Clone(cached.inputs), keyConfigKey)
+				}
+			}
+			cached.newReader = func(configMap map[string]string) Reader {
+				readerConfigMap := configMap
+				if muxReader.isDependent() {
+					// the wrapped reader only gets its own inputs, and not the cache key
+					readerConfigMap = make(map[string]string, len(muxReader.inputs))
+					for _, key := range muxReader.inputs {
+						if value, ok := configMap[key]; ok {
+							readerConfigMap[key] = value
+						}
+					}
+				}
+
+				r := NewCachedReader(muxReader.newReader(readerConfigMap), cachePath, opts...)
+				r.configMap = configMap
+				return r
+			}
+			configMux.readers = append(configMux.readers, cached)
+		}
+	}
+}
+
 // WithCustomReader lets you add your own custom reader to the mux
 // your custom reader just needs to implement the "Reader" interface
 // The difference between WithCustomReader and WithCustomLazyReader is:
@@ -77,13 +534,11 @@ func WithBitwardenSecretReader() func(*ConfigMux) {
 // This function is useful if your reader can be initialized at
 // the same time as the mux.
 // WithCustomLazyReader is more powerful, but WithCustomReader is
-// simpler to use and syntactically terse
+// simpler to use and syntactically terse.
+// Since it doesn't depend on other readers, r may be read concurrently with them
 func WithCustomReader(r Reader) func(*ConfigMux) {
 	return func(configMux *ConfigMux) {
-		configMux.readerFns = append(
-			configMux.readerFns,
-			func(_ map[string]string) Reader { return r },
-		)
+		configMux.addIndependentReader(Source{Reader: "custom"}, func() Reader { return r })
 	}
 }
 
@@ -98,6 +553,67 @@ func WithCustomReader(r Reader) func(*ConfigMux) {
 // It uses this map to try to authenticate to Bitwarden
 func WithCustomLazyReader(fn func(configMap map[string]string) Reader) func(*ConfigMux) {
 	return func(configMux *ConfigMux) {
-		configMux.readerFns = append(configMux.readerFns, fn)
+		configMux.addReader(Source{Reader: "custom"}, fn)
+	}
+}
+
+// WithCustomDependentReader is like WithCustomLazyReader, but for a reader that only needs the config keys in keys,
+// like a secret manager that needs an access token.
+// fn only receives those keys, so that the reader isn't handed secrets that it doesn't need.
+// If any of the keys is missing or empty, the reader is skipped and the mux reports a diagnostic for it,
+// like "custom #2: Skipped: needs api_token", where 2 is the position of the reader in the mux.
+// Readers with declared keys that are next to each other are read concurrently. If a reader changes a key
+// that a reader after it needs, the later reader is read again with the new value
+func WithCustomDependentReader(keys []string, fn func(configMap map[string]string) Reader) func(*ConfigMux) {
+	return func(configMux *ConfigMux) {
+		needs := make([][]string, len(keys))
+		for i, key := range keys {
+			needs[i] = []string{key}
+		}
+		configMux.addDependentReader(Source{Reader: "custom"}, keys, needs, fn)
+	}
+}
+
+// WithMandatoryReader makes the readers added by readerOpt fail when they are not configured, instead of being skipped.
+// For example, WithMandatoryReader(WithBitwardenSecretReader()) makes a missing bitwarden_access_token an error
+// that matches ErrNotConfigured, instead of a "bitwarden #3: Skipped: needs bitwarden_access_token" diagnostic.
+// It only applies to readers that declare the keys they need, like the Bitwarden, Vault and AWS readers
+// and readers added with WithCustomDependentReader
+func WithMandatoryReader(readerOpt func(*ConfigMux)) func(*ConfigMux) {
+	return func(configMux *ConfigMux) {
+		inner := ConfigMux{}
+		readerOpt(&inner)
+
+		for _, muxReader := range inner.readers {
+			muxReader.mandatory = true
+			configMux.readers = append(configMux.readers, muxReader)
+		}
+	}
+}
+
+// WithNameMapper sets the naming convention of the config keys of the mux.
+// Struct fields without a `conflux` or `json` tag are matched with mapper(field name) when unmarshalling
+// from the mux, and the keys of every reader are converted with mapper before they are merged.
+// For example, with WithNameMapper(SnakeCase), the field SSHPort matches ssh_port, and SSH_PORT
+// from the environment, ssh-port from a yaml file and sshPort from a JSON document are all read as ssh_port.
+// Keys that readers like the Bitwarden reader need, like bitwarden_access_token, are converted too
+func WithNameMapper(mapper NameMapper) func(*ConfigMux) {
+	return func(configMux *ConfigMux) {
+		configMux.nameMapper = mapper
+	}
+}
+
+// WithReaderNameMapper converts the keys of the readers added by readerOpt with mapper, instead of
+// with the NameMapper of the mux. For example, WithReaderNameMapper(SnakeCase, WithHTTPReader(url))
+// reads sshPort from a JSON document as ssh_port, without converting the keys of the other readers
+func WithReaderNameMapper(mapper NameMapper, readerOpt func(*ConfigMux)) func(*ConfigMux) {
+	return func(configMux *ConfigMux) {
+		inner := ConfigMux{}
+		readerOpt(&inner)
+
+		for _, muxReader := range inner.readers {
+			muxReader.nameMapper = mapper
+			configMux.readers = append(configMux.readers, muxReader)
+		}
 	}
 }
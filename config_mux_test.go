@@ -2,9 +2,15 @@ package conflux
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"testing/fstest"
+	"time"
 )
 
 type testConfig struct {
@@ -197,3 +203,353 @@ func TestConfigMux_Diagnostics(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigMux_ContinueOnError(t *testing.T) {
+	errCustom := errors.New("custom reader error")
+	newOpts := func() []func(*ConfigMux) {
+		return []func(*ConfigMux){
+			WithYAMLFileReader("config/all.yml", WithFileSystem(fstest.MapFS{
+				"config/all.yml": {Data: []byte("ssh_port: 22\ngateway_address: 10.0.0.1\n")},
+			})),
+			WithCustomReader(failingReader{err: errCustom}),
+			WithYAMLFileReader("config/secret.yml", WithFileSystem(permissionFS{fstest.MapFS{
+				"config/secret.yml": {Data: []byte("physical_nic: eth0\n")},
+			}})),
+			WithEnvReader(WithEnviron([]string{"SSH_PORT=2222"})),
+		}
+	}
+
+	t.Run("stops at the first error by default", func(t *testing.T) {
+		target := testConfig{}
+		diagnostics, err := Unmarshal(NewConfigMux(newOpts()...), &target)
+		if !errors.Is(err, errCustom) || errors.Is(err, fs.ErrPermission) {
+			t.Fatalf("expected only the error of the custom reader, got %v", err)
+		}
+		if diagnostics != nil {
+			t.Errorf("expected no diagnostics, got %v", diagnostics)
+		}
+	})
+
+	t.Run("collects every error", func(t *testing.T) {
+		target := testConfig{}
+		diagnostics, err := Unmarshal(NewConfigMux(append(newOpts(), WithContinueOnError())...), &target)
+		for _, expected := range []error{errCustom, fs.ErrPermission, ErrReaderFailed, ErrInvalidFields} {
+			if !errors.Is(err, expected) {
+				t.Errorf("expected error to match %v, got %v", expected, err)
+			}
+		}
+
+		if target.SSHPort != "2222" || target.GatewayAddress != "10.0.0.1" {
+			t.Errorf("expected the config of the readers that succeeded, got %+v", target)
+		}
+
+		expected := map[string]string{
+			"custom #2":    StatusFailed + ": error reading: " + errCustom.Error(),
+			"yaml #3":      StatusFailed + " (config/secret.yml): error reading config file(config/secret.yml): open config/secret.yml: permission denied",
+			"ssh_port":     StatusLoaded,
+			"physical_nic": StatusMissing,
+		}
+		for k, v := range expected {
+			if diagnostics[k] != v {
+				t.Errorf("expected diagnostics[%q] to be %q, got %q", k, v, diagnostics[k])
+			}
+		}
+	})
+}
+
+// barrierReader only returns its config once every reader that shares its barrier has started reading
+type barrierReader struct {
+	barrier   *sync.WaitGroup
+	configMap map[string]string
+}
+
+func (r barrierReader) Read() (ReadResult, error) {
+	r.barrier.Done()
+	done := make(chan struct{})
+	go func() {
+		r.barrier.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return NewSimpleReadResult(r.configMap), nil
+	case <-time.After(5 * time.Second):
+		return nil, errors.New("readers were not read concurrently")
+	}
+}
+
+func TestConfigMux_Concurrent(t *testing.T) {
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+
+	var lazyConfigMap map[string]string
+	configMux := NewConfigMux(
+		WithCustomReader(barrierReader{barrier: &barrier, configMap: map[string]string{"ssh_port": "22", "physical_nic": "eth0"}}),
+		WithCustomLazyReader(func(configMap map[string]string) Reader {
+			lazyConfigMap = maps.Clone(configMap)
+			return newMapReader(map[string]string{"gateway_address": "10.0.0.1"})
+		}),
+		WithCustomReader(barrierReader{barrier: &barrier, configMap: map[string]string{"ssh_port": "2222"}}),
+	)
+
+	configMap := make(map[string]string)
+	if _, err := Unmarshal(configMux, &configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"ssh_port": "2222", "physical_nic": "eth0", "gateway_address": "10.0.0.1"}
+	if !maps.Equal(configMap, expected) {
+		t.Errorf("expected readers to be merged in priority order %v, got %v", expected, configMap)
+	}
+
+	expectedLazy := map[string]string{"ssh_port": "22", "physical_nic": "eth0"}
+	if !maps.Equal(lazyConfigMap, expectedLazy) {
+		t.Errorf("expected lazy reader to see only the readers before it %v, got %v", expectedLazy, lazyConfigMap)
+	}
+}
+
+func TestConfigMux_ReadSourced(t *testing.T) {
+	var reads atomic.Int32
+	configMux := NewConfigMux(
+		WithEnvReader(WithEnviron([]string{"SSH_PORT=22"})),
+		WithCustomLazyReader(func(configMap map[string]string) Reader {
+			// every read sets a different key, so that a later read would show up in the result of an earlier one
+			return newMapReader(map[string]string{fmt.Sprintf("key_%d", reads.Add(1)): "value"})
+		}),
+	)
+
+	first, err := configMux.ReadSourced()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := configMux.ReadSourced()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]Source{"ssh_port": {Reader: "env"}, "key_1": {Reader: "custom"}}
+	if sources := first.Sources(); !maps.Equal(sources, expected) {
+		t.Errorf("expected the sources of the first read to be %v, got %v", expected, sources)
+	}
+	if values := first.Explain("key_2"); len(values) != 0 {
+		t.Errorf("expected the first read not to explain a key of the second one, got %v", values)
+	}
+	if values := second.Explain("key_2"); len(values) != 1 || values[0].Source.Reader != "custom" {
+		t.Errorf("expected the second read to explain key_2, got %v", values)
+	}
+}
+
+func TestConfigMux_DependentReaders(t *testing.T) {
+	newYAMLReader := func(data string) func(*ConfigMux) {
+		return WithYAMLFileReader("config/all.yml", WithFileSystem(fstest.MapFS{"config/all.yml": {Data: []byte(data)}}))
+	}
+
+	t.Run("only gets its keys", func(t *testing.T) {
+		var received map[string]string
+		configMux := NewConfigMux(
+			newYAMLReader("api_token: abc\ndb_password: hunter2\n"),
+			WithCustomDependentReader([]string{"API_TOKEN"}, func(configMap map[string]string) Reader {
+				received = maps.Clone(configMap)
+				return newMapReader(map[string]string{"ssh_port": "22"})
+			}),
+		)
+
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(configMux, &configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := map[string]string{"api_token": "abc"}; !maps.Equal(received, expected) {
+			t.Errorf("expected reader to get %v, got %v", expected, received)
+		}
+		if configMap["ssh_port"] != "22" {
+			t.Errorf("expected config of the reader to be merged, got %v", configMap)
+		}
+	})
+
+	t.Run("is skipped when a key is missing", func(t *testing.T) {
+		configMux := NewConfigMux(
+			newYAMLReader("ssh_port: 22\n"),
+			WithCustomDependentReader([]string{"api_token"}, func(configMap map[string]string) Reader {
+				t.Error("expected reader not to be created")
+				return failingReader{err: errors.New("unexpected read")}
+			}),
+		)
+
+		configMap := make(map[string]string)
+		diagnostics, err := Unmarshal(configMux, &configMap)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := StatusSkipped + ": needs api_token"; diagnostics["custom #2"] != expected {
+			t.Errorf("expected diagnostics[\"custom #2\"] to be %q, got %q", expected, diagnostics["custom #2"])
+		}
+	})
+
+	t.Run("readers of the same kind and config keys with their name don't clash", func(t *testing.T) {
+		newReader := func(configMap map[string]string) Reader { return newMapReader(nil) }
+		configMux := NewConfigMux(
+			newYAMLReader("custom: value\n"),
+			WithCustomDependentReader([]string{"api_token"}, newReader),
+			WithCustomDependentReader([]string{"db_token"}, newReader),
+		)
+
+		result, err := configMux.ReadSourced()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		diagnostics, err := Unmarshal(result, &map[string]string{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := map[string]string{"custom #2": StatusSkipped + ": needs api_token", "custom #3": StatusSkipped + ": needs db_token"}
+		if !maps.Equal(diagnostics, expected) {
+			t.Errorf("expected diagnostics to be %v, got %v", expected, diagnostics)
+		}
+		if configMap := result.GetConfigMap(); configMap["custom"] != "value" {
+			t.Errorf("expected the config key custom to be kept, got %v", configMap)
+		}
+	})
+
+	t.Run("are read concurrently", func(t *testing.T) {
+		var barrier sync.WaitGroup
+		barrier.Add(2)
+		newReader := func(configMap map[string]string) Reader {
+			return barrierReader{barrier: &barrier, configMap: map[string]string{"gateway_address": configMap["api_token"]}}
+		}
+
+		configMux := NewConfigMux(
+			newYAMLReader("api_token: abc\n"),
+			WithCustomDependentReader([]string{"api_token"}, newReader),
+			WithCustomDependentReader([]string{"api_token"}, newReader),
+		)
+
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(configMux, &configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if configMap["gateway_address"] != "abc" {
+			t.Errorf("expected gateway_address to be abc, got %v", configMap)
+		}
+	})
+
+	t.Run("is read again when a reader in front of it changes its keys", func(t *testing.T) {
+		var mu sync.Mutex
+		var tokens []string
+		configMux := NewConfigMux(
+			newYAMLReader("api_token: stale\n"),
+			WithCustomDependentReader([]string{"api_token"}, func(configMap map[string]string) Reader {
+				return newMapReader(map[string]string{"api_token": "fresh"})
+			}),
+			WithCustomDependentReader([]string{"api_token"}, func(configMap map[string]string) Reader {
+				mu.Lock()
+				tokens = append(tokens, configMap["api_token"])
+				mu.Unlock()
+				return newMapReader(map[string]string{"ssh_port": configMap["api_token"]})
+			}),
+		)
+
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(configMux, &configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if configMap["ssh_port"] != "fresh" {
+			t.Errorf("expected the reader to see the new value, got %v", configMap)
+		}
+		// the read with the stale value was started concurrently, so it may finish after the read again
+		slices.Sort(tokens)
+		if expected := []string{"fresh", "stale"}; !slices.Equal(tokens, expected) {
+			t.Errorf("expected the reader to be created with %v, got %v", expected, tokens)
+		}
+	})
+
+	t.Run("a re-check only starts its own reader", func(t *testing.T) {
+		var created atomic.Int32
+		configMux := NewConfigMux(
+			newYAMLReader("api_token: abc\n"),
+			WithCustomDependentReader([]string{"api_token"}, func(configMap map[string]string) Reader {
+				return newMapReader(map[string]string{"db_token": "def"})
+			}),
+			// db_token is missing when the readers are started, so this reader is only started when it is reached
+			WithCustomDependentReader([]string{"db_token"}, func(configMap map[string]string) Reader {
+				return newMapReader(map[string]string{"db_password": configMap["db_token"]})
+			}),
+			WithCustomDependentReader([]string{"api_token"}, func(configMap map[string]string) Reader {
+				created.Add(1)
+				return newMapReader(map[string]string{"ssh_port": "22"})
+			}),
+		)
+
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(configMux, &configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if configMap["db_password"] != "def" || configMap["ssh_port"] != "22" {
+			t.Errorf("expected the config of every reader to be merged, got %v", configMap)
+		}
+		if n := created.Load(); n != 1 {
+			t.Errorf("expected the last reader to be created once, got %d", n)
+		}
+	})
+}
+
+func TestConfigMux_NotConfigured(t *testing.T) {
+	newOpts := func(mandatory bool) []func(*ConfigMux) {
+		bitwardenOpt := WithBitwardenSecretReader()
+		if mandatory {
+			bitwardenOpt = WithMandatoryReader(bitwardenOpt)
+		}
+		return []func(*ConfigMux){
+			WithYAMLFileReader("config/all.yml", WithFileSystem(fstest.MapFS{
+				"config/all.yml": {Data: []byte("ssh_port: 22\n")},
+			})),
+			WithEnvReader(WithEnviron([]string{"BWS_ACCESS_TOKEN=123"})),
+			bitwardenOpt,
+		}
+	}
+
+	t.Run("is skipped with a single diagnostic", func(t *testing.T) {
+		configMap := make(map[string]string)
+		diagnostics, err := Unmarshal(NewConfigMux(newOpts(false)...), &configMap)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := map[string]string{"bitwarden #3": StatusSkipped + ": needs bitwarden_organization_id"}
+		if !maps.Equal(diagnostics, expected) {
+			t.Errorf("expected diagnostics to be %v, got %v", expected, diagnostics)
+		}
+	})
+
+	t.Run("is an error when the reader is mandatory", func(t *testing.T) {
+		configMap := make(map[string]string)
+		_, err := Unmarshal(NewConfigMux(newOpts(true)...), &configMap)
+		for _, expected := range []error{ErrNotConfigured, ErrReaderFailed} {
+			if !errors.Is(err, expected) {
+				t.Errorf("expected error to match %v, got %v", expected, err)
+			}
+		}
+
+		var readerErr *ReaderError
+		if !errors.As(err, &readerErr) || readerErr.Reader != "bitwarden" {
+			t.Errorf("expected a bitwarden ReaderError, got %v", err)
+		}
+	})
+}
+
+func TestConfigMux_CredentialKeys(t *testing.T) {
+	configMux := NewConfigMux(
+		WithEnvReader(),
+		WithHTTPReader("https://config.example.com", WithBearerTokenFromConfig("config_token")),
+		WithVaultReader(),
+		WithNameMapper(KebabCase),
+	)
+
+	keys := configMux.CredentialKeys()
+	for _, expected := range []string{"conflux-cache-key", "config-token", "vault-token", "vault-secret-id"} {
+		if !slices.Contains(keys, expected) {
+			t.Errorf("expected credential keys to contain %s, got %v", expected, keys)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package conflux
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// DumpFormat is the format that Dump and DumpMap render the config as
+type DumpFormat string
+
+const (
+	DumpFormatTable DumpFormat = "table"
+	DumpFormatYAML  DumpFormat = "yaml"
+	DumpFormatJSON  DumpFormat = "json"
+)
+
+// DumpOptions configures Dump and DumpMap
+type DumpOptions struct {
+	// Format is the output format. By default, it is DumpFormatTable
+	Format DumpFormat
+	// Sources is the source of each config value, usually from SourcedReadResult.Sources of ConfigMux.ReadSourced
+	// Values that came from a secret source are masked
+	Sources map[string]Source
+	// SecretKeys are keys whose values are masked regardless of their source
+	SecretKeys []string
+	// Diagnostics is the diagnostic map returned by Unmarshal. It is used for the status of each key
+	Diagnostics map[string]string
+	// NameMapper is the NameMapper of the ConfigMux that target was unmarshalled from, if any.
+	// Untagged fields are shown with the key returned by it, see WithNameMapper
+	NameMapper NameMapper
+	// RevealLast is the number of trailing characters of masked values that are shown.
+	// By default, masked values are hidden entirely.
+	// Values that are too short to hide at least as many characters as are shown are hidden entirely
+	RevealLast int
+}
+
+// DumpEntry is a single config value in the output of Dump and DumpMap
+type DumpEntry struct {
+	Key    string `json:"key" yaml:"key"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+	Status string `json:"status" yaml:"status"`
+}
+
+// Dump renders the config values of target, which should have been filled by Unmarshal.
+// Values of Secret fields, fields tagged with `secret:"true"`, and values that came from
+// a secret source, like Bitwarden, are masked
+func Dump(target any, opts DumpOptions) (string, error) {
+	fields, err := getMappedFields(target, opts.NameMapper)
+	if err != nil {
+		return "", fmt.Errorf("error getting tagged fields: %w", err)
+	}
+
+	entries := make([]DumpEntry, 0, len(fields))
+	for _, field := range fields {
+		if !field.Type.IsExported() || !isStringField(field.Type) {
+			continue
+		}
+
+		tag := field.tag
+		value := fieldString(field.Value)
+
+		source := opts.Sources[strings.ToLower(tag)]
+		if isSecretField(field.Type) || source.Secret || opts.isSecretKey(tag) {
+			value = mask(value, opts.RevealLast)
+		}
+
+		entries = append(entries, DumpEntry{
+			Key:    tag,
+			Value:  value,
+			Source: source.Reader,
+			Status: dumpStatus(tag, value, opts.Diagnostics),
+		})
+	}
+
+	return renderDump(entries, opts.Format)
+}
+
+// DumpMap renders the values of a config map, like the one read by a ConfigMux
+// Values that came from a secret source, like Bitwarden, are masked
+func DumpMap(configMap map[string]string, opts DumpOptions) (string, error) {
+	entries := make([]DumpEntry, 0, len(configMap))
+	for key, value := range configMap {
+		source := opts.Sources[strings.ToLower(key)]
+		if source.Secret || opts.isSecretKey(key) {
+			value = mask(value, opts.RevealLast)
+		}
+
+		entries = append(entries, DumpEntry{
+			Key:    key,
+			Value:  value,
+			Source: source.Reader,
+			Status: dumpStatus(key, value, opts.Diagnostics),
+		})
+	}
+
+	return renderDump(entries, opts.Format)
+}
+
+func (o DumpOptions) isSecretKey(key string) bool {
+	return slices.ContainsFunc(o.SecretKeys, func(k string) bool { return strings.EqualFold(k, key) })
+}
+
+func dumpStatus(key, value string, diagnostics map[string]string) string {
+	if status, ok := diagnostics[key]; ok {
+		return status
+	} else if value == "" {
+		return StatusMissing
+	}
+	return StatusLoaded
+}
+
+func mask(value string, revealLast int) string {
+	if value == "" {
+		return ""
+	}
+
+	runes := []rune(value)
+	if revealLast <= 0 || len(runes) < revealLast*2 {
+		return redacted
+	}
+	return "****" + string(runes[len(runes)-revealLast:])
+}
+
+func renderDump(entries []DumpEntry, format DumpFormat) (string, error) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	switch format {
+	case DumpFormatTable, "":
+		return dumpToTable(entries), nil
+	case DumpFormatYAML:
+		out, err := yaml.Marshal(entries)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling dump to yaml: %w", err)
+		}
+		return string(out), nil
+	case DumpFormatJSON:
+		out, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshalling dump to json: %w", err)
+		}
+		return string(out) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown dump format: %s", format)
+	}
+}
+
+func dumpToTable(entries []DumpEntry) string {
+	headers := []string{"KEY", "VALUE", "SOURCE", "STATUS"}
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, []string{entry.Key, entry.Value, entry.Source, entry.Status})
+	}
+
+	return formatTable(headers, rows, false)
+}
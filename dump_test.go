@@ -0,0 +1,72 @@
+package conflux
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type dumpConfig struct {
+	SSHPort    string `json:"ssh_port" required:"true"`
+	APIToken   string `json:"api_token" secret:"true"`
+	DBPassword Secret `json:"db_password"`
+	AppKey     string `json:"app_key_0"`
+}
+
+func TestDump(t *testing.T) {
+	store := newFakeSecretStore(1, 0)
+	r := NewConfigMux(
+		WithYAMLFileReader("config/all.yml", WithFileSystem(fstest.MapFS{
+			"config/all.yml": {Data: []byte("ssh_port: 2222\napi_token: abcdefgh1234\ndb_password: hunter2hunter2\n")},
+		})),
+		WithEnvReader(WithEnviron([]string{"BITWARDEN_ACCESS_TOKEN=valid", "BITWARDEN_ORGANIZATION_ID=org"})),
+		WithBitwardenSecretReader(WithBitwardenClientFactory(store.factory)),
+	)
+
+	result, err := r.ReadSourced()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	target := dumpConfig{}
+	diagnostics, err := Unmarshal(result, &target)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := Dump(&target, DumpOptions{Format: DumpFormatJSON, Sources: result.Sources(), Diagnostics: diagnostics, RevealLast: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var entries []DumpEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("unexpected error unmarshalling dump: %v", err)
+	}
+
+	expected := []DumpEntry{
+		{Key: "api_token", Value: "****1234", Source: "yaml", Status: StatusLoaded},
+		{Key: "app_key_0", Value: redacted, Source: "bitwarden", Status: StatusLoaded},
+		{Key: "db_password", Value: "****ter2", Source: "yaml", Status: StatusLoaded},
+		{Key: "ssh_port", Value: "2222", Source: "yaml", Status: StatusLoaded},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, entries)
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], entries[i])
+		}
+	}
+
+	table, err := Dump(&target, DumpOptions{Sources: result.Sources(), Diagnostics: diagnostics})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, leaked := range []string{"abcdefgh1234", "hunter2", "value-0"} {
+		if strings.Contains(table, leaked) {
+			t.Errorf("expected table to not contain %s:\n%s", leaked, table)
+		}
+	}
+}
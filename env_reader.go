@@ -9,7 +9,8 @@ import (
 var _ Reader = envReader{}
 
 type envReader struct {
-	environ []string
+	environ   []string
+	separator string
 }
 
 // NewEnvReader creates a new reader which gets key-value pairs from the environment
@@ -34,6 +35,9 @@ func (r envReader) Read() (ReadResult, error) {
 		}
 
 		key, value, _ := split(entry)
+		if r.separator != "" {
+			key = strings.ReplaceAll(key, r.separator, ".")
+		}
 		envAsMap[key] = value
 	}
 	return NewSimpleReadResult(envAsMap), nil
@@ -58,3 +62,11 @@ func WithEnviron(environ []string) func(*envReader) {
 		r.environ = environ
 	}
 }
+
+// WithEnvKeySeparator makes the envReader read separator in variable names as a dot
+// For example, with "__", DB__HOST is read as db.host, like the names written by Export with the same KeySeparator
+func WithEnvKeySeparator(separator string) func(*envReader) {
+	return func(r *envReader) {
+		r.separator = separator
+	}
+}
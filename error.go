@@ -2,6 +2,102 @@ package conflux
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dannyvelas/conflux/internal/client"
+	"github.com/goccy/go-yaml"
 )
 
 var ErrInvalidFields = errors.New("invalid or missing fields")
+
+// ErrReaderFailed matches any error of a reader of a ConfigMux with errors.Is
+// Use errors.As with a *ReaderError to find out which reader failed
+var ErrReaderFailed = errors.New("reader failed")
+
+// ErrNotConfigured is matched by the error of a reader that was made mandatory with WithMandatoryReader,
+// but whose configuration, like bitwarden_access_token, is missing
+var ErrNotConfigured = errors.New("reader not configured")
+
+// ErrAuthentication is matched by the errors of secret managers and remote services that reject
+// their credentials, like a Bitwarden access token that isn't valid, or an http reader that gets a 401.
+// Network failures don't match it, so they can be told apart
+var ErrAuthentication = client.ErrAuthentication
+
+// ReaderError is returned when a reader fails to read its config
+// It matches ErrReaderFailed with errors.Is, and unwraps to the error of the reader
+type ReaderError struct {
+	// Reader is the name of the reader, like "yaml", "bitwarden" or "custom"
+	Reader string
+	// Source is the file, URL or address that the reader was reading from, if it is known
+	Source string
+	Err    error
+}
+
+func (e *ReaderError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("%s reader failed: %v", e.Reader, e.Err)
+	}
+	return fmt.Sprintf("%s reader failed (%s): %v", e.Reader, e.Source, e.Err)
+}
+
+func (e *ReaderError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ReaderError) Is(target error) bool {
+	return target == ErrReaderFailed
+}
+
+// FileParseError is returned when a config file can't be parsed
+// Use errors.As to get it from the error of a reader or of Unmarshal
+type FileParseError struct {
+	Path string
+	// Line and Column are the 1-based position of the error in the file. They are 0 if the position is unknown
+	Line   int
+	Column int
+	// Snippet is the line of the file where the error is
+	Snippet string
+	// Err is the error of the parser
+	Err error
+}
+
+func (e *FileParseError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("error parsing config file (%s): %s", e.Path, e.message())
+	}
+	return fmt.Sprintf("error parsing config file (%s:%d:%d): %s", e.Path, e.Line, e.Column, e.message())
+}
+
+func (e *FileParseError) Unwrap() error {
+	return e.Err
+}
+
+// message returns the error of the parser without the source that yaml errors usually include
+func (e *FileParseError) message() string {
+	var yamlErr yaml.Error
+	if errors.As(e.Err, &yamlErr) {
+		return yamlErr.GetMessage()
+	}
+	return e.Err.Error()
+}
+
+// NewFileParseError creates a FileParseError from an error of the yaml parser while parsing data, the contents of path.
+// The position and snippet are only set if the parser reported where the error is.
+// This lets tools built on conflux report errors in their own yaml files, like a manifest, the same way
+func NewFileParseError(path string, data []byte, err error) *FileParseError {
+	parseErr := &FileParseError{Path: path, Err: err}
+
+	var yamlErr yaml.Error
+	if !errors.As(err, &yamlErr) || yamlErr.GetToken() == nil {
+		return parseErr
+	}
+
+	position := yamlErr.GetToken().Position
+	parseErr.Line, parseErr.Column = position.Line, position.Column
+	if lines := strings.Split(string(data), "\n"); position.Line >= 1 && position.Line <= len(lines) {
+		parseErr.Snippet = strings.TrimRight(lines[position.Line-1], "\r")
+	}
+
+	return parseErr
+}
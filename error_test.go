@@ -0,0 +1,168 @@
+package conflux
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// permissionFS is a file system whose files can be listed, but not opened
+type permissionFS struct {
+	fs.StatFS
+}
+
+func (f permissionFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read() (ReadResult, error) {
+	return nil, r.err
+}
+
+func TestReaderErrors(t *testing.T) {
+	awsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"com.amazon.coral.service#UnrecognizedClientException","message":"The security token included in the request is invalid."}`))
+	}))
+	t.Cleanup(awsServer.Close)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(httpServer.Close)
+
+	closedServer := httptest.NewServer(http.NotFoundHandler())
+	closedServer.Close()
+
+	vaultServer := newVaultTestServer(t)
+	store := newFakeSecretStore(1, 0)
+	store.failures.Store(100)
+	errCustom := errors.New("custom reader error")
+
+	cases := []struct {
+		name           string
+		opts           []func(*ConfigMux)
+		expectedReader string
+		expectedSource string
+		expectedIs     []error
+		unexpectedIs   []error
+	}{
+		{
+			name: "yaml file without permission",
+			opts: []func(*ConfigMux){WithYAMLFileReader("config/all.yml", WithFileSystem(permissionFS{fstest.MapFS{
+				"config/all.yml": {Data: []byte("ssh_port: 22\n")},
+			}}))},
+			expectedReader: "yaml",
+			expectedSource: "config/all.yml",
+			expectedIs:     []error{fs.ErrPermission},
+		},
+		{
+			name:           "custom reader",
+			opts:           []func(*ConfigMux){WithCustomReader(failingReader{err: fmt.Errorf("wrapped: %w", errCustom)})},
+			expectedReader: "custom",
+			expectedIs:     []error{errCustom},
+			unexpectedIs:   []error{ErrAuthentication},
+		},
+		{
+			name: "bitwarden with an invalid access token",
+			opts: []func(*ConfigMux){
+				WithEnvReader(WithEnviron([]string{"BITWARDEN_ACCESS_TOKEN=invalid", "BITWARDEN_ORGANIZATION_ID=org"})),
+				WithBitwardenSecretReader(WithBitwardenClientFactory(store.factory)),
+			},
+			expectedReader: "bitwarden",
+			expectedSource: "https://api.bitwarden.com",
+			expectedIs:     []error{ErrAuthentication},
+		},
+		{
+			name: "bitwarden that keeps failing",
+			opts: []func(*ConfigMux){
+				WithEnvReader(WithEnviron([]string{"BITWARDEN_ACCESS_TOKEN=valid", "BITWARDEN_ORGANIZATION_ID=org"})),
+				WithBitwardenSecretReader(WithBitwardenClientFactory(store.factory), WithBitwardenRetries(1, time.Millisecond)),
+			},
+			expectedReader: "bitwarden",
+			expectedSource: "https://api.bitwarden.com",
+			unexpectedIs:   []error{ErrAuthentication},
+		},
+		{
+			name: "vault with a token that is denied",
+			opts: []func(*ConfigMux){
+				WithEnvReader(WithEnviron([]string{"VAULT_ADDRESS=" + vaultServer.URL, "VAULT_TOKEN=wrong", "VAULT_PATH=app/prod"})),
+				WithVaultReader(),
+			},
+			expectedReader: "vault",
+			expectedSource: vaultServer.URL,
+			expectedIs:     []error{ErrAuthentication},
+		},
+		{
+			name: "vault with an invalid approle secret id",
+			opts: []func(*ConfigMux){
+				WithEnvReader(WithEnviron([]string{"VAULT_ADDRESS=" + vaultServer.URL, "VAULT_ROLE_ID=role", "VAULT_SECRET_ID=wrong", "VAULT_PATH=app/prod"})),
+				WithVaultReader(),
+			},
+			expectedReader: "vault",
+			expectedSource: vaultServer.URL,
+			expectedIs:     []error{ErrAuthentication},
+		},
+		{
+			name: "aws with unrecognized credentials",
+			opts: []func(*ConfigMux){
+				WithEnvReader(WithEnviron([]string{"AWS_REGION=us-east-1", "AWS_ACCESS_KEY_ID=AKID", "AWS_SECRET_ACCESS_KEY=secret", "AWS_ENDPOINT_URL=" + awsServer.URL, "AWS_SECRET_ID=app/prod"})),
+				WithAWSReader(),
+			},
+			expectedReader: "aws",
+			expectedSource: awsServer.URL,
+			expectedIs:     []error{ErrAuthentication},
+		},
+		{
+			name:           "http reader that is unauthorized",
+			opts:           []func(*ConfigMux){WithHTTPReader(httpServer.URL)},
+			expectedReader: "http",
+			expectedSource: httpServer.URL,
+			expectedIs:     []error{ErrAuthentication},
+		},
+		{
+			name:           "http reader that can't connect",
+			opts:           []func(*ConfigMux){WithHTTPReader(closedServer.URL, WithRetries(0, 0))},
+			expectedReader: "http",
+			expectedSource: closedServer.URL,
+			unexpectedIs:   []error{ErrAuthentication},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Unmarshal(NewConfigMux(tc.opts...), &map[string]string{})
+			if !errors.Is(err, ErrReaderFailed) {
+				t.Fatalf("expected error to match ErrReaderFailed, got %v", err)
+			}
+
+			var readerErr *ReaderError
+			if !errors.As(err, &readerErr) {
+				t.Fatalf("expected a *ReaderError, got %v", err)
+			}
+			if readerErr.Reader != tc.expectedReader || readerErr.Source != tc.expectedSource {
+				t.Errorf("expected reader %s with source %q, got %s with source %q", tc.expectedReader, tc.expectedSource, readerErr.Reader, readerErr.Source)
+			}
+
+			for _, target := range tc.expectedIs {
+				if !errors.Is(err, target) {
+					t.Errorf("expected error to match %v, got %v", target, err)
+				}
+			}
+			for _, target := range tc.unexpectedIs {
+				if errors.Is(err, target) {
+					t.Errorf("expected error to not match %v, got %v", target, err)
+				}
+			}
+		})
+	}
+}
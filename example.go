@@ -0,0 +1,154 @@
+package conflux
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// KeyDescription describes a config key of a struct
+type KeyDescription struct {
+	// Key is the config key, as matched by Unmarshal
+	Key string
+	// EnvVar is the name of the environment variable that sets the key
+	EnvVar string
+	// Type is "string" or "secret"
+	Type string
+	// Required is true if the field is tagged with `required:"true"`
+	Required bool
+	// Default is the value of the field in the struct that was described
+	// It is always empty for secrets
+	Default string
+	// Description is the value of the `desc` tag of the field
+	Description string
+	// Enum is the list of allowed values of a field tagged with `enum:"a,b,c"`
+	Enum []string
+}
+
+// DescribeKeys returns a description of every config key of target, in the order the fields are declared.
+// Like Unmarshal, it uses the `conflux` tag, then the `json` tag, then the field name converted with mapper as the key.
+// mapper should be the NameMapper of the ConfigMux that target is unmarshalled from, or nil if it has none.
+// Defaults are the values of the fields of target, so pass in a struct with its defaults filled in
+func DescribeKeys(target any, mapper NameMapper) ([]KeyDescription, error) {
+	fields, err := getMappedFields(target, mapper)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tagged fields: %w", err)
+	}
+
+	descriptions := make([]KeyDescription, 0, len(fields))
+	for _, field := range fields {
+		if !field.Type.IsExported() || !isStringField(field.Type) {
+			continue
+		}
+
+		description := KeyDescription{
+			Key:         field.tag,
+			EnvVar:      EnvVarName(field.tag),
+			Type:        "string",
+			Description: field.Type.Tag.Get("desc"),
+			Enum:        enumValues(field.Type),
+		}
+		_, description.Required = field.Type.Tag.Lookup("required")
+		if isSecretField(field.Type) {
+			description.Type = "secret"
+		} else {
+			description.Default = field.Value.String()
+		}
+
+		descriptions = append(descriptions, description)
+	}
+
+	return descriptions, nil
+}
+
+// GenerateExampleYAML returns a commented example yaml config file, like config.example.yml, for target
+// See DescribeKeys for mapper
+func GenerateExampleYAML(target any, mapper NameMapper) (string, error) {
+	descriptions, err := DescribeKeys(target, mapper)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, d := range descriptions {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		writeComment(&sb, d)
+		// a JSON string is also a valid double-quoted yaml string
+		value, _ := json.Marshal(d.Default)
+		sb.WriteString(d.Key + ": " + string(value) + "\n")
+	}
+
+	return sb.String(), nil
+}
+
+// GenerateExampleEnv returns a commented example dotenv file, like .env.example, for target
+// See DescribeKeys for mapper
+func GenerateExampleEnv(target any, mapper NameMapper) (string, error) {
+	descriptions, err := DescribeKeys(target, mapper)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, d := range descriptions {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		writeComment(&sb, d)
+		value := ""
+		if d.Default != "" {
+			value = quoteDotenv(d.Default)
+		}
+		sb.WriteString(d.EnvVar + "=" + value + "\n")
+	}
+
+	return sb.String(), nil
+}
+
+// GenerateReference returns a Markdown table that documents every config key of target
+// See DescribeKeys for mapper
+func GenerateReference(target any, mapper NameMapper) (string, error) {
+	descriptions, err := DescribeKeys(target, mapper)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Key | Env var | Type | Required | Default | Description |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, d := range descriptions {
+		required := "no"
+		if d.Required {
+			required = "yes"
+		}
+
+		cells := []string{d.Key, d.EnvVar, d.Type, required, d.Default, d.Description}
+		for i, cell := range cells {
+			cells[i] = escapeMarkdown(cell)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+
+	return sb.String(), nil
+}
+
+func writeComment(sb *strings.Builder, d KeyDescription) {
+	for line := range strings.SplitSeq(d.Description, "\n") {
+		if line != "" {
+			sb.WriteString("# " + line + "\n")
+		}
+	}
+
+	var notes []string
+	if d.Required {
+		notes = append(notes, "required")
+	}
+	if d.Type == "secret" {
+		notes = append(notes, "secret")
+	}
+	if len(notes) > 0 {
+		sb.WriteString("# (" + strings.Join(notes, ", ") + ")\n")
+	}
+}
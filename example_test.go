@@ -0,0 +1,75 @@
+package conflux
+
+import (
+	"testing"
+)
+
+type exampleConfig struct {
+	SSHPort    string `json:"ssh_port" required:"true" desc:"port of the SSH server"`
+	DBHost     string `conflux:"db.host" desc:"hostname of the database"`
+	DBPassword Secret `json:"db_password" required:"true" desc:"password of the database"`
+	Ignored    int    `json:"ignored"`
+}
+
+func TestGenerate(t *testing.T) {
+	target := exampleConfig{DBHost: "localhost"}
+
+	cases := []struct {
+		name     string
+		generate func(any, NameMapper) (string, error)
+		expected string
+	}{
+		{
+			name:     "yaml",
+			generate: GenerateExampleYAML,
+			expected: `# port of the SSH server
+# (required)
+ssh_port: ""
+
+# hostname of the database
+db.host: "localhost"
+
+# password of the database
+# (required, secret)
+db_password: ""
+`,
+		},
+		{
+			name:     "env",
+			generate: GenerateExampleEnv,
+			expected: `# port of the SSH server
+# (required)
+SSH_PORT=
+
+# hostname of the database
+DB_HOST=localhost
+
+# password of the database
+# (required, secret)
+DB_PASSWORD=
+`,
+		},
+		{
+			name:     "reference",
+			generate: GenerateReference,
+			expected: `| Key | Env var | Type | Required | Default | Description |
+| --- | --- | --- | --- | --- | --- |
+| ssh\_port | SSH\_PORT | string | yes |  | port of the SSH server |
+| db.host | DB\_HOST | string | no | localhost | hostname of the database |
+| db\_password | DB\_PASSWORD | secret | yes |  | password of the database |
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := tc.generate(&target, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package conflux
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ExportFormat is the format that Export and ExportMap write the config as
+type ExportFormat string
+
+const (
+	// ExportFormatDotenv writes KEY=value lines, like the ones read by docker-compose
+	ExportFormatDotenv ExportFormat = "dotenv"
+	// ExportFormatShell writes export KEY=value lines that can be sourced by a POSIX shell
+	ExportFormatShell ExportFormat = "shell"
+	// ExportFormatYAML writes a flat yaml map, like db.host: localhost
+	ExportFormatYAML ExportFormat = "yaml"
+	// ExportFormatNestedYAML writes a yaml map where dotted keys are nested, like db: {host: localhost}
+	ExportFormatNestedYAML ExportFormat = "yaml-nested"
+	// ExportFormatJSON writes a flat JSON object
+	ExportFormatJSON ExportFormat = "json"
+)
+
+// ExportOptions configures Export and ExportMap
+type ExportOptions struct {
+	// Format is the output format. By default, it is ExportFormatDotenv
+	Format ExportFormat
+	// Prefix is prepended to variable names of the dotenv and shell formats
+	Prefix string
+	// KeySeparator replaces the dots of keys in variable names of the dotenv and shell formats.
+	// By default, dots become underscores, like in EnvVarName. Use "__" with WithEnvKeySeparator("__")
+	// to read the variables back as the same keys
+	KeySeparator string
+	// NameMapper is the NameMapper of the ConfigMux that target was unmarshalled from, if any.
+	// Untagged fields are written with the key returned by it, see WithNameMapper
+	NameMapper NameMapper
+}
+
+// Export writes the config values of target, which should have been filled by Unmarshal,
+// in a format that can be handed to other tools.
+// Unlike Dump, secrets are not redacted
+func Export(target any, opts ExportOptions) (string, error) {
+	fields, err := getMappedFields(target, opts.NameMapper)
+	if err != nil {
+		return "", fmt.Errorf("error getting tagged fields: %w", err)
+	}
+
+	configMap := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if !field.Type.IsExported() || !isStringField(field.Type) {
+			continue
+		}
+
+		configMap[field.tag] = fieldString(field.Value)
+	}
+
+	return ExportMap(configMap, opts)
+}
+
+// ExportMap writes the values of a config map, like the one read by a ConfigMux,
+// in a format that can be handed to other tools.
+// Unlike DumpMap, secrets are not redacted
+func ExportMap(configMap map[string]string, opts ExportOptions) (string, error) {
+	keys := make([]string, 0, len(configMap))
+	for key := range configMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch opts.Format {
+	case ExportFormatDotenv, "":
+		var sb strings.Builder
+		for _, key := range keys {
+			sb.WriteString(opts.Prefix + envVarName(key, opts.KeySeparator) + "=" + quoteDotenv(configMap[key]) + "\n")
+		}
+		return sb.String(), nil
+	case ExportFormatShell:
+		var sb strings.Builder
+		for _, key := range keys {
+			sb.WriteString("export " + opts.Prefix + envVarName(key, opts.KeySeparator) + "=" + quoteShell(configMap[key]) + "\n")
+		}
+		return sb.String(), nil
+	case ExportFormatYAML:
+		flat := make(yaml.MapSlice, 0, len(keys))
+		for _, key := range keys {
+			flat = append(flat, yaml.MapItem{Key: key, Value: configMap[key]})
+		}
+		return marshalYAML(flat)
+	case ExportFormatNestedYAML:
+		nested, err := nestKeys(configMap, keys)
+		if err != nil {
+			return "", err
+		}
+		return marshalYAML(nested)
+	case ExportFormatJSON:
+		out, err := json.MarshalIndent(configMap, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshalling export to json: %w", err)
+		}
+		return string(out) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown export format: %s", opts.Format)
+	}
+}
+
+// EnvVarName converts a config key to an environment variable name
+// For example, db.host becomes DB_HOST
+func EnvVarName(key string) string {
+	return envVarName(key, "")
+}
+
+// envVarName is like EnvVarName, but dots become separator, unless it is empty
+func envVarName(key, separator string) string {
+	if separator != "" {
+		key = strings.ReplaceAll(key, ".", separator)
+	}
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, key))
+}
+
+func marshalYAML(v yaml.MapSlice) (string, error) {
+	if len(v) == 0 {
+		return "{}\n", nil
+	}
+
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling export to yaml: %w", err)
+	}
+	return string(out), nil
+}
+
+// nestKeys turns dotted keys into nested yaml maps
+// it fails if a key is both a value and the parent of other keys, like db and db.host
+func nestKeys(configMap map[string]string, keys []string) (yaml.MapSlice, error) {
+	root := map[string]any{}
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		node := root
+		for i, part := range parts[:len(parts)-1] {
+			child, exists := node[part]
+			if !exists {
+				child = map[string]any{}
+				node[part] = child
+			}
+			childMap, ok := child.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("key %s is both a value and a parent of other keys", strings.Join(parts[:i+1], "."))
+			}
+			node = childMap
+		}
+
+		leaf := parts[len(parts)-1]
+		if _, exists := node[leaf]; exists {
+			return nil, fmt.Errorf("key %s is both a value and a parent of other keys", key)
+		}
+		node[leaf] = configMap[key]
+	}
+	return toMapSlice(root), nil
+}
+
+// toMapSlice converts nested maps to a yaml.MapSlice with sorted keys
+func toMapSlice(m map[string]any) yaml.MapSlice {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make(yaml.MapSlice, 0, len(keys))
+	for _, key := range keys {
+		value := m[key]
+		if child, ok := value.(map[string]any); ok {
+			value = toMapSlice(child)
+		}
+		out = append(out, yaml.MapItem{Key: key, Value: value})
+	}
+	return out
+}
+
+// quoteDotenv quotes a value for a dotenv file
+// single quotes are used when possible because their contents are never interpolated
+func quoteDotenv(value string) string {
+	if value != "" && strings.IndexFunc(value, func(r rune) bool { return !isShellSafe(r) }) == -1 {
+		return value
+	}
+
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// quoteShell quotes a value for a POSIX shell
+func quoteShell(value string) string {
+	if value != "" && strings.IndexFunc(value, func(r rune) bool { return !isShellSafe(r) }) == -1 {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// isShellSafe reports whether r never needs to be quoted in a shell word
+func isShellSafe(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune("_-./:@%+,", r)
+}
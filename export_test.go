@@ -0,0 +1,136 @@
+package conflux
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportMap(t *testing.T) {
+	configMap := map[string]string{
+		"ssh_port":    "22",
+		"db.host":     "localhost",
+		"db.password": "it's a $ecret",
+		"greeting":    "hello world",
+		"motd":        "line 1\nline \"2\"",
+	}
+
+	cases := []struct {
+		name     string
+		opts     ExportOptions
+		expected string
+	}{
+		{
+			name: "dotenv",
+			opts: ExportOptions{Format: ExportFormatDotenv, Prefix: "APP_"},
+			expected: `APP_DB_HOST=localhost
+APP_DB_PASSWORD="it's a \$ecret"
+APP_GREETING='hello world'
+APP_MOTD="line 1\nline \"2\""
+APP_SSH_PORT=22
+`,
+		},
+		{
+			name: "shell",
+			opts: ExportOptions{Format: ExportFormatShell},
+			expected: `export DB_HOST=localhost
+export DB_PASSWORD='it'\''s a $ecret'
+export GREETING='hello world'
+export MOTD='line 1
+line "2"'
+export SSH_PORT=22
+`,
+		},
+		{
+			name: "yaml",
+			opts: ExportOptions{Format: ExportFormatYAML},
+			expected: `db.host: localhost
+db.password: it's a $ecret
+greeting: hello world
+motd: |-
+  line 1
+  line "2"
+ssh_port: "22"
+`,
+		},
+		{
+			name: "nested yaml",
+			opts: ExportOptions{Format: ExportFormatNestedYAML},
+			expected: `db:
+  host: localhost
+  password: it's a $ecret
+greeting: hello world
+motd: |-
+  line 1
+  line "2"
+ssh_port: "22"
+`,
+		},
+		{
+			name: "json",
+			opts: ExportOptions{Format: ExportFormatJSON},
+			expected: `{
+  "db.host": "localhost",
+  "db.password": "it's a $ecret",
+  "greeting": "hello world",
+  "motd": "line 1\nline \"2\"",
+  "ssh_port": "22"
+}
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := ExportMap(configMap, tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out)
+			}
+		})
+	}
+}
+
+func TestExportMap_NestedConflict(t *testing.T) {
+	_, err := ExportMap(map[string]string{"db": "x", "db.host": "localhost"}, ExportOptions{Format: ExportFormatNestedYAML})
+	if err == nil {
+		t.Fatalf("expected an error for a key that is both a value and a parent")
+	}
+}
+
+func TestExport(t *testing.T) {
+	target := dumpConfig{SSHPort: "22", DBPassword: NewSecret("hunter2")}
+
+	out, err := Export(&target, ExportOptions{Format: ExportFormatDotenv})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "API_TOKEN=''\nAPP_KEY_0=''\nDB_PASSWORD=hunter2\nSSH_PORT=22\n"
+	if out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestExportMap_KeySeparator(t *testing.T) {
+	configMap := map[string]string{"ssh_port": "22", "db.host": "localhost", "db.max_conns": "10"}
+
+	out, err := ExportMap(configMap, ExportOptions{Format: ExportFormatDotenv, KeySeparator: "__"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "DB__HOST=localhost\nDB__MAX_CONNS=10\nSSH_PORT=22\n"; out != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	environ := strings.Split(strings.TrimSpace(out), "\n")
+	readMap := make(map[string]string)
+	if _, err := Unmarshal(NewConfigMux(WithEnvReader(WithEnviron(environ), WithEnvKeySeparator("__"))), &readMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(readMap, configMap) {
+		t.Errorf("expected the exported variables to be read back as %v, got %v", configMap, readMap)
+	}
+}
@@ -0,0 +1,126 @@
+package conflux
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var _ Reader = (*flagReader)(nil)
+
+type flagReader struct {
+	args   []string
+	name   string
+	target any
+	output io.Writer
+	mapper NameMapper
+}
+
+// NewFlagReader creates a new reader which gets key-value pairs from command-line flags.
+// Flags are derived from the struct passed in with WithFlagTarget: a field tagged
+// `json:"ssh_port"` can be set with --ssh-port. Only flags that were explicitly set
+// on the command-line are returned, so that they don't override values from
+// other readers with empty defaults.
+// If -help or -h is passed, a usage message is printed and Read returns an error
+// that wraps flag.ErrHelp.
+func NewFlagReader(args []string, opts ...func(*flagReader)) *flagReader {
+	r := flagReader{
+		args:   args,
+		name:   os.Args[0],
+		output: os.Stderr,
+	}
+
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	return &r
+}
+
+func (r *flagReader) Read() (ReadResult, error) {
+	if r.target == nil {
+		return nil, fmt.Errorf("flag reader has no target struct, use WithFlagTarget to set one")
+	}
+
+	fields, err := getMappedFields(r.target, r.mapper)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tagged fields: %w", err)
+	}
+
+	flagSet := flag.NewFlagSet(r.name, flag.ContinueOnError)
+	flagSet.SetOutput(r.output)
+
+	flagToTag := make(map[string]string, len(fields))
+	values := make(map[string]*string, len(fields))
+	for _, field := range fields {
+		if !isStringField(field.Type) {
+			continue
+		}
+
+		tag := field.tag
+		flagName := toFlagName(tag)
+		if _, ok := flagToTag[flagName]; ok {
+			return nil, fmt.Errorf("config keys %s and %s map to the same flag --%s", flagToTag[flagName], tag, flagName)
+		}
+		flagToTag[flagName] = tag
+		values[flagName] = flagSet.String(flagName, "", flagUsage(tag, field.reflectField))
+	}
+
+	flagSet.Usage = func() {
+		fmt.Fprintf(flagSet.Output(), "Usage of %s:\n", r.name)
+		flagSet.PrintDefaults()
+	}
+
+	if err := flagSet.Parse(r.args); err != nil {
+		return nil, fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	configMap := make(map[string]string)
+	flagSet.Visit(func(f *flag.Flag) {
+		configMap[flagToTag[f.Name]] = *values[f.Name]
+	})
+
+	return NewSimpleReadResult(configMap), nil
+}
+
+func (r *flagReader) setFieldNameMapper(mapper NameMapper) {
+	r.mapper = mapper
+}
+
+func toFlagName(key string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(key))
+}
+
+func flagUsage(tag string, field reflectField) string {
+	usage := "sets config key " + tag
+	if _, ok := field.Type.Tag.Lookup("required"); ok {
+		usage += " (required)"
+	}
+	return usage
+}
+
+// WithFlagTarget sets the struct that the flag reader derives its flags from
+// This should be the same struct that you pass to Unmarshal
+func WithFlagTarget(target any) func(*flagReader) {
+	return func(r *flagReader) {
+		r.target = target
+	}
+}
+
+// WithFlagSetName sets the program name that is shown in the usage message
+// By default, it uses os.Args[0]
+func WithFlagSetName(name string) func(*flagReader) {
+	return func(r *flagReader) {
+		r.name = name
+	}
+}
+
+// WithFlagOutput sets where the usage message and parsing errors are written to
+// By default, it uses os.Stderr
+func WithFlagOutput(output io.Writer) func(*flagReader) {
+	return func(r *flagReader) {
+		r.output = output
+	}
+}
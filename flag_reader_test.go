@@ -0,0 +1,100 @@
+package conflux
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFlagReader(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		env      []string
+		expected testConfig
+	}{
+		{
+			// the env reader reads SSH_PORT and the flag reader reads ssh_port, so the mux
+			// has to normalize keys for the flag to deterministically take precedence
+			name: "flag overrides env and file",
+			args: []string{"--ssh-port", "2200", "-gateway-address=10.0.0.254"},
+			env:  []string{"SSH_PORT=9999"},
+			expected: testConfig{
+				SSHPublicKeyPath:     "~/.ssh/id_ed25519.pub",
+				NodeCIDRAddress:      "10.0.0.50/24",
+				GatewayAddress:       "10.0.0.254",
+				PhysicalNIC:          "enx6c1ff7135975",
+				SSHPort:              "2200",
+				AutoUpdateRebootTime: "05:00",
+			},
+		},
+		{
+			name: "unset flags don't override env",
+			args: []string{},
+			env:  []string{"SSH_PORT=9999"},
+			expected: testConfig{
+				SSHPublicKeyPath:     "~/.ssh/id_ed25519.pub",
+				NodeCIDRAddress:      "10.0.0.50/24",
+				GatewayAddress:       "10.0.0.1",
+				PhysicalNIC:          "enx6c1ff7135975",
+				SSHPort:              "9999",
+				AutoUpdateRebootTime: "05:00",
+			},
+		},
+	}
+
+	fs := fstest.MapFS{
+		"config/all.yml": {Data: []byte("ssh_port: 17031\nssh_public_key_path: \"~/.ssh/id_ed25519.pub\"\ngateway_address: 10.0.0.1\nphysical_nic: \"enx6c1ff7135975\"\nauto_update_reboot_time: \"05:00\"\nnode_cidr_address: 10.0.0.50/24\n")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := testConfig{}
+			r := NewConfigMux(
+				WithYAMLFileReader("config/all.yml", WithFileSystem(fs)),
+				WithEnvReader(WithEnviron(tc.env)),
+				WithFlagReader(tc.args, WithFlagTarget(&target)),
+			)
+			if _, err := Unmarshal(r, &target); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if target != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, target)
+			}
+		})
+	}
+}
+
+func TestFlagReader_Help(t *testing.T) {
+	var out bytes.Buffer
+	target := testConfig{}
+	r := NewFlagReader([]string{"-help"}, WithFlagTarget(&target), WithFlagSetName("app"), WithFlagOutput(&out))
+
+	if _, err := Unmarshal(r, &target); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected error to be %v, got %v", flag.ErrHelp, err)
+	}
+
+	usage := out.String()
+	for _, expected := range []string{"Usage of app:", "-ssh-port", "sets config key ssh_port (required)"} {
+		if !strings.Contains(usage, expected) {
+			t.Errorf("expected usage to contain %q, got:\n%s", expected, usage)
+		}
+	}
+}
+
+// the mux and Unmarshal wrap the errors of their readers with %w,
+// so that an application can tell that -help was passed and exit cleanly
+func TestFlagReader_HelpThroughMux(t *testing.T) {
+	target := testConfig{}
+	r := NewConfigMux(
+		WithEnvReader(WithEnviron([]string{})),
+		WithFlagReader([]string{"-help"}, WithFlagTarget(&target), WithFlagOutput(&bytes.Buffer{})),
+	)
+
+	if _, err := Unmarshal(r, &target); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected error to be %v, got %v", flag.ErrHelp, err)
+	}
+}
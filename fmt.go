@@ -1,69 +1,206 @@
 package conflux
 
 import (
-	"bytes"
-	"html/template"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 )
 
+// DiagnosticsRenderer renders a diagnostic map as a user-friendly report
+type DiagnosticsRenderer interface {
+	Render(diagnostics map[string]string) string
+}
+
+var (
+	_ DiagnosticsRenderer = TableRenderer{}
+	_ DiagnosticsRenderer = MarkdownRenderer{}
+	_ DiagnosticsRenderer = JSONRenderer{}
+)
+
+// TableRenderer renders diagnostics as a plain-text table
+type TableRenderer struct {
+	// Color makes missing statuses red and loaded statuses green with ANSI escape codes
+	// This should only be used when writing to a terminal
+	Color bool
+	// Sources groups rows by the reader that reported them, usually from SourcedReadResult.DiagnosticSources of ConfigMux.ReadSourced
+	// If it is nil, there is no SOURCE column
+	Sources map[string]string
+}
+
+// MarkdownRenderer renders diagnostics as a Markdown table
+type MarkdownRenderer struct {
+	// Sources groups rows by the reader that reported them, usually from SourcedReadResult.DiagnosticSources of ConfigMux.ReadSourced
+	// If it is nil, there is no SOURCE column
+	Sources map[string]string
+}
+
+// JSONRenderer renders diagnostics as a JSON array of objects
+type JSONRenderer struct {
+	// Sources groups rows by the reader that reported them, usually from SourcedReadResult.DiagnosticSources of ConfigMux.ReadSourced
+	// If it is nil, there is no source field
+	Sources map[string]string
+}
+
+// diagnosticRow is a single row of a rendered diagnostic map
+type diagnosticRow struct {
+	Source  string `json:"source,omitempty"`
+	Subject string `json:"subject"`
+	Status  string `json:"status"`
+}
+
 // DiagnosticsToTable takes a diagnostic map and returns it as a pretty-printed formatted table
 // This is useful as a user-friendly report of missing and found configuration values
 func DiagnosticsToTable(data map[string]string) string {
-	headerCol1 := "SUBJECT"
-	headerCol2 := "STATUS"
+	return TableRenderer{}.Render(data)
+}
 
-	maxKeyLen := len(headerCol1)
-	maxValueLen := len(headerCol2)
+func (r TableRenderer) Render(diagnostics map[string]string) string {
+	rows := diagnosticRows(diagnostics, r.Sources)
 
-	for k, v := range data {
-		if len(k) > maxKeyLen {
-			maxKeyLen = len(k)
-		}
-		if len(v) > maxValueLen {
-			maxValueLen = len(v)
-		}
+	headers := []string{"SUBJECT", "STATUS"}
+	if r.Sources != nil {
+		headers = append([]string{"SOURCE"}, headers...)
 	}
 
-	type tableContext struct {
-		Data       map[string]string
-		HeaderCol1 string
-		HeaderCol2 string
-		KeyWidth   int
-		ValueWidth int
-		Line       string
+	cells := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		cells = append(cells, row.cells(r.Sources != nil))
 	}
 
-	// "| " (2) + maxKeyLen + " | " (3) + maxValueLen + " " (1)
-	totalLineLength := maxKeyLen + maxValueLen + 6
-	line := strings.Repeat("-", totalLineLength)
+	return formatTable(headers, cells, r.Color)
+}
+
+func (r MarkdownRenderer) Render(diagnostics map[string]string) string {
+	rows := diagnosticRows(diagnostics, r.Sources)
 
-	ctx := tableContext{
-		Data:       data,
-		HeaderCol1: headerCol1,
-		HeaderCol2: headerCol2,
-		KeyWidth:   maxKeyLen,
-		ValueWidth: maxValueLen,
-		Line:       line,
+	headers := []string{"SUBJECT", "STATUS"}
+	if r.Sources != nil {
+		headers = append([]string{"SOURCE"}, headers...)
 	}
 
-	const tableTmpl = `{{ .Line }}
-| {{ printf "%-*s" .KeyWidth .HeaderCol1 }} | {{ printf "%-*s" .ValueWidth .HeaderCol2 }} |
-{{ .Line }}
-{{- range $key, $val := .Data }}
-| {{ printf "%-*s" $.KeyWidth $key }} | {{ printf "%-*s" $.ValueWidth $val }} |
-{{- end }}
-{{ .Line }}
-`
+	var sb strings.Builder
+	sb.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+	for _, row := range rows {
+		cells := row.cells(r.Sources != nil)
+		for i, cell := range cells {
+			cells[i] = escapeMarkdown(cell)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
 
-	tmpl, err := template.New("table").Parse(tableTmpl)
+	return sb.String()
+}
+
+func (r JSONRenderer) Render(diagnostics map[string]string) string {
+	rows := diagnosticRows(diagnostics, r.Sources)
+
+	out, err := json.MarshalIndent(rows, "", "  ")
 	if err != nil {
+		// a slice of structs with string fields can always be marshalled
 		panic(err)
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, ctx); err != nil {
-		panic(err)
+	return string(out) + "\n"
+}
+
+// formatTable renders rows as a plain-text table
+// If color is true, the last column of each row is treated as a status and colored accordingly
+func formatTable(headers []string, rows [][]string, color bool) string {
+	widths := make([]int, len(headers))
+	for _, row := range append([][]string{headers}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
 	}
 
-	return buf.String()
+	// "| " (2) + each column + " | " (3) between columns + " |" (2)
+	totalLineLength := 4 + 3*(len(headers)-1)
+	for _, width := range widths {
+		totalLineLength += width
+	}
+	line := strings.Repeat("-", totalLineLength)
+
+	var sb strings.Builder
+	writeRow := func(row []string, color bool) {
+		padded := make([]string, len(row))
+		for i, cell := range row {
+			padded[i] = fmt.Sprintf("%-*s", widths[i], cell)
+		}
+		if color {
+			status := len(padded) - 1
+			padded[status] = colorize(row[status], padded[status])
+		}
+		sb.WriteString("| " + strings.Join(padded, " | ") + " |\n")
+	}
+
+	sb.WriteString(line + "\n")
+	writeRow(headers, false)
+	sb.WriteString(line + "\n")
+	for _, row := range rows {
+		writeRow(row, color)
+	}
+	sb.WriteString(line + "\n")
+
+	return sb.String()
+}
+
+// diagnosticRows returns the rows of a diagnostic map sorted by source and then by subject
+func diagnosticRows(diagnostics map[string]string, sources map[string]string) []diagnosticRow {
+	rows := make([]diagnosticRow, 0, len(diagnostics))
+	for subject, status := range diagnostics {
+		rows = append(rows, diagnosticRow{Source: sources[subject], Subject: subject, Status: status})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Source != rows[j].Source {
+			return rows[i].Source < rows[j].Source
+		}
+		return rows[i].Subject < rows[j].Subject
+	})
+
+	return rows
+}
+
+func (r diagnosticRow) cells(withSource bool) []string {
+	if withSource {
+		return []string{r.Source, r.Subject, r.Status}
+	}
+	return []string{r.Subject, r.Status}
+}
+
+func colorize(status, cell string) string {
+	const (
+		red   = "\x1b[31m"
+		green = "\x1b[32m"
+		reset = "\x1b[0m"
+	)
+
+	switch {
+	case status == StatusMissing, strings.HasPrefix(status, StatusInvalid), strings.HasPrefix(status, StatusFailed):
+		return red + cell + reset
+	case status == StatusLoaded, strings.HasPrefix(status, "Loaded"):
+		return green + cell + reset
+	default:
+		return cell
+	}
+}
+
+// escapeMarkdown escapes the characters that would otherwise be interpreted
+// as Markdown or HTML inside of a table cell
+func escapeMarkdown(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		switch c {
+		case '\\', '`', '*', '_', '|', '<', '>', '[', ']':
+			sb.WriteRune('\\')
+			sb.WriteRune(c)
+		case '\n':
+			sb.WriteString("<br>")
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
 }
@@ -0,0 +1,76 @@
+package conflux
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticsRenderers(t *testing.T) {
+	diagnostics := map[string]string{
+		"ssh_port":          StatusLoaded,
+		"config/<host>.yml": "Skipped: Not Found",
+		"gateway_address":   StatusMissing,
+	}
+	sources := map[string]string{"config/<host>.yml": "yaml"}
+
+	cases := []struct {
+		name     string
+		renderer DiagnosticsRenderer
+		expected string
+	}{
+		{
+			name:     "table doesn't escape html",
+			renderer: TableRenderer{},
+			expected: "| config/<host>.yml | Skipped: Not Found |\n",
+		},
+		{
+			name:     "colored table",
+			renderer: TableRenderer{Color: true},
+			expected: "| gateway_address   | \x1b[31mmissing           \x1b[0m |\n",
+		},
+		{
+			name:     "table grouped by source",
+			renderer: TableRenderer{Sources: sources},
+			expected: "|        | ssh_port          | loaded             |\n" +
+				"| yaml   | config/<host>.yml | Skipped: Not Found |\n",
+		},
+		{
+			name:     "markdown escapes html",
+			renderer: MarkdownRenderer{},
+			expected: "| SUBJECT | STATUS |\n| --- | --- |\n| config/\\<host\\>.yml | Skipped: Not Found |\n| gateway\\_address | missing |\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := tc.renderer.Render(diagnostics)
+			if !strings.Contains(out, tc.expected) {
+				t.Errorf("expected output to contain:\n%q\ngot:\n%q", tc.expected, out)
+			}
+		})
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	diagnostics := map[string]string{"b": StatusLoaded, "a": StatusMissing, "c": StatusLoaded}
+
+	var rows []diagnosticRow
+	if err := json.Unmarshal([]byte(JSONRenderer{Sources: map[string]string{"a": "env"}}.Render(diagnostics)), &rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []diagnosticRow{
+		{Subject: "b", Status: StatusLoaded},
+		{Subject: "c", Status: StatusLoaded},
+		{Source: "env", Subject: "a", Status: StatusMissing},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, rows)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], rows[i])
+		}
+	}
+}
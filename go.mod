@@ -5,3 +5,5 @@ go 1.25.1
 require github.com/goccy/go-yaml v1.19.2
 
 require github.com/bitwarden/sdk-go v1.0.2
+
+require golang.org/x/crypto v0.54.0
@@ -13,9 +13,10 @@ type reflectField struct {
 }
 
 // fromMap takes a map[string]string and writes it to "dst"
-// "dst" could either be a map[string]string or a struct with only string fields
+// "dst" could either be a map[string]string or a struct with string and Secret fields
 // if "dst" is a map[string]string, then entries in "src" are copied to "dst"
-func fromMap(src map[string]string, dst any) error {
+// untagged struct fields are matched with the key returned by mapper, see fieldKey
+func fromMap(src map[string]string, dst any, mapper NameMapper) error {
 	val := reflect.ValueOf(dst)
 
 	// We must have a pointer to a struct, or a pointer to a map to be able to set values
@@ -49,16 +50,23 @@ func fromMap(src map[string]string, dst any) error {
 		field := structType.Field(i)
 		fieldVal := dstVal.Field(i)
 
-		// we can only set the field if it is capitalized (exported) and of type string
-		if !fieldVal.CanSet() || field.Type.Kind() != reflect.String {
+		// we can only set the field if it is capitalized (exported) and of type string or Secret
+		if !fieldVal.CanSet() || !isStringField(field) {
 			continue
 		}
 
 		// get tag value
-		configTag := queryForTags(field, "conflux", []string{"json"})
+		configTag := fieldKey(field, mapper)
 
 		// If the tag exists as a key in our source map, set the field
-		if val, exists := normalizedSrc[strings.ToLower(configTag)]; exists {
+		val, exists := normalizedSrc[strings.ToLower(configTag)]
+		if !exists {
+			continue
+		}
+
+		if field.Type == secretType {
+			fieldVal.Set(reflect.ValueOf(NewSecret(val)))
+		} else {
 			fieldVal.SetString(val)
 		}
 	}
@@ -70,6 +78,26 @@ func fromMap(src map[string]string, dst any) error {
 // the value of tag `tagName`. each value is a reflect.Value.
 // if `tagName` is not found, it will iterate through `fallbackTags` until it finds a value
 func getTagToFieldMap(v any, tagName string, fallbackTags ...string) (map[string]reflectField, error) {
+	fields, err := getTaggedFields(v, tagName, fallbackTags...)
+	if err != nil {
+		return nil, err
+	}
+
+	tagToFieldMap := make(map[string]reflectField, len(fields))
+	for _, field := range fields {
+		tagToFieldMap[field.tag] = field.reflectField
+	}
+
+	return tagToFieldMap, nil
+}
+
+type taggedField struct {
+	reflectField
+	tag string
+}
+
+// getTaggedFields is like getTagToFieldMap, but it returns the fields in the order they are declared
+func getTaggedFields(v any, tagName string, fallbackTags ...string) ([]taggedField, error) {
 	rv := reflect.ValueOf(v)
 
 	// If a pointer is passed, get the underlying element (the actual struct)
@@ -82,18 +110,30 @@ func getTagToFieldMap(v any, tagName string, fallbackTags ...string) (map[string
 		return nil, fmt.Errorf("expected a struct as argument")
 	}
 
-	tagToFieldMap := make(map[string]reflectField)
-
 	rt := rv.Type()
+	fields := make([]taggedField, 0, rt.NumField())
 	for i := 0; i < rt.NumField(); i++ {
 		field := rt.Field(i)
 
 		foundTag := queryForTags(field, tagName, fallbackTags)
 
-		tagToFieldMap[foundTag] = reflectField{field, rv.Field(i)}
+		fields = append(fields, taggedField{reflectField{field, rv.Field(i)}, foundTag})
 	}
 
-	return tagToFieldMap, nil
+	return fields, nil
+}
+
+// getMappedFields is like getTaggedFields, but untagged fields get the key returned by mapper, see fieldKey
+func getMappedFields(v any, mapper NameMapper) ([]taggedField, error) {
+	fields, err := getTaggedFields(v, "conflux", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range fields {
+		fields[i].tag = fieldKey(fields[i].Type, mapper)
+	}
+	return fields, nil
 }
 
 func queryForTags(field reflect.StructField, tagName string, fallbackTags []string) string {
@@ -115,3 +155,55 @@ func mergeMaps[K comparable, V any](m1, m2 map[K]V) map[K]V {
 	maps.Copy(newMap, m2)
 	return newMap
 }
+
+// lookupKey finds the value of key in configMap, ignoring case
+func lookupKey(configMap map[string]string, key string) (string, bool) {
+	if v, ok := configMap[key]; ok {
+		return v, true
+	}
+	for k, v := range configMap {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// lowerKeys returns a copy of keys in lowercase, like the keys of the config map of a ConfigMux
+func lowerKeys(keys []string) []string {
+	if keys == nil {
+		return nil
+	}
+	lowered := make([]string, len(keys))
+	for i, key := range keys {
+		lowered[i] = strings.ToLower(key)
+	}
+	return lowered
+}
+
+// isStringField reports whether a struct field can be filled from a config value
+func isStringField(field reflect.StructField) bool {
+	return field.Type.Kind() == reflect.String || field.Type == secretType
+}
+
+// enumValues returns the allowed values of a field tagged with `enum:"a,b,c"`, or nil if it has no such tag
+func enumValues(field reflect.StructField) []string {
+	enum, ok := field.Tag.Lookup("enum")
+	if !ok {
+		return nil
+	}
+
+	values := strings.Split(enum, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// fieldString returns the value of a string or Secret field
+func fieldString(v reflect.Value) string {
+	if secret, ok := v.Interface().(Secret); ok {
+		return secret.Reveal()
+	}
+	return v.String()
+}
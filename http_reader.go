@@ -0,0 +1,293 @@
+package conflux
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var _ Reader = (*httpReader)(nil)
+
+type httpReader struct {
+	url        string
+	configMap  map[string]string
+	headers    map[string]string
+	headerKeys map[string]configHeader
+	certFile   string
+	keyFile    string
+	caFile     string
+	retries    int
+	backoff    time.Duration
+	timeout    time.Duration
+	cache      *httpCache
+	sleep      func(time.Duration)
+}
+
+// configHeader is a header whose value is read from a config key
+type configHeader struct {
+	key    string
+	prefix string
+}
+
+type httpCache struct {
+	mu        sync.Mutex
+	etag      string
+	configMap map[string]string
+}
+
+// NewHTTPReader creates a new reader which gets key-value pairs from a JSON document served at url.
+// Nested JSON objects are flattened into keys joined by dots, so {"db": {"host": "x"}} is read as db.host.
+// The reader remembers the ETag of the last response and sends it with If-None-Match, so an
+// unchanged document isn't downloaded twice.
+func NewHTTPReader(url string, opts ...func(*httpReader)) *httpReader {
+	r := httpReader{
+		url:        url,
+		headers:    make(map[string]string),
+		headerKeys: make(map[string]configHeader),
+		backoff:    500 * time.Millisecond,
+		timeout:    30 * time.Second,
+		cache:      &httpCache{},
+		sleep:      time.Sleep,
+	}
+
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	return &r
+}
+
+func (r *httpReader) Read() (ReadResult, error) {
+	headers, diagnostics := maps.Clone(r.headers), make(map[string]string)
+	valid := true
+	for name, header := range r.headerKeys {
+		value, ok := lookupKey(r.configMap, header.key)
+		if !ok || value == "" {
+			diagnostics[header.key] = StatusMissing
+			valid = false
+			continue
+		}
+		diagnostics[header.key] = StatusLoaded
+		headers[name] = header.prefix + value
+	}
+	if !valid {
+		return NewDiagnosticReadResult(nil, diagnostics), ErrInvalidFields
+	}
+
+	httpClient, err := r.newClient()
+	if err != nil {
+		return nil, fmt.Errorf("error creating http client: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= r.retries; attempt++ {
+		if attempt > 0 {
+			r.sleep(r.backoff * time.Duration(1<<(attempt-1)))
+		}
+
+		configMap, retry, err := r.fetch(httpClient, headers)
+		if err == nil {
+			return NewDiagnosticReadResult(configMap, diagnostics), nil
+		} else if !retry {
+			return nil, &ReaderError{Reader: "http", Source: r.url, Err: err}
+		}
+		lastErr = err
+	}
+
+	return nil, &ReaderError{Reader: "http", Source: r.url, Err: fmt.Errorf("giving up after %d attempts: %w", r.retries+1, lastErr)}
+}
+
+// fetch makes a single request. the second return value reports whether the error is transient
+func (r *httpReader) fetch(httpClient *http.Client, headers map[string]string) (map[string]string, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+
+	if r.cache.etag != "" {
+		req.Header.Set("If-None-Match", r.cache.etag)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && r.cache.configMap != nil:
+		return maps.Clone(r.cache.configMap), false, nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, false, fmt.Errorf("%w: status code %d", ErrAuthentication, resp.StatusCode)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, true, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	configMap, err := flattenJSON(body)
+	if err != nil {
+		return nil, false, fmt.Errorf("error flattening response body: %w", err)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		r.cache.etag, r.cache.configMap = etag, maps.Clone(configMap)
+	}
+
+	return configMap, false, nil
+}
+
+func (r *httpReader) newClient() (*http.Client, error) {
+	if r.certFile == "" && r.caFile == "" {
+		return &http.Client{Timeout: r.timeout}, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if r.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if r.caFile != "" {
+		caCert, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ca certificate (%s): %w", r.caFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in %s", r.caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: r.timeout, Transport: transport}, nil
+}
+
+func flattenJSON(data []byte) (map[string]string, error) {
+	var v any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, fmt.Errorf("error decoding json: %w", err)
+	}
+
+	object, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a json object, got %T", v)
+	}
+
+	configMap := make(map[string]string)
+	if err := flatten("", object, configMap); err != nil {
+		return nil, err
+	}
+	return configMap, nil
+}
+
+func flatten(prefix string, object map[string]any, dst map[string]string) error {
+	for k, v := range object {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flatten(key, v, dst); err != nil {
+				return err
+			}
+		case string:
+			dst[key] = v
+		case nil:
+			dst[key] = ""
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("error encoding value of key %s: %w", key, err)
+			}
+			dst[key] = string(encoded)
+		}
+	}
+	return nil
+}
+
+// WithHeader adds a static header to every request of the http reader
+func WithHeader(name, value string) func(*httpReader) {
+	return func(r *httpReader) {
+		r.headers[name] = value
+	}
+}
+
+// WithHeaderFromConfig sets a header to the value of a config key that was read by a previous reader
+// If the config key is missing, the http reader reports it in its diagnostics and is skipped
+func WithHeaderFromConfig(name, key string) func(*httpReader) {
+	return func(r *httpReader) {
+		r.headerKeys[name] = configHeader{key: key}
+	}
+}
+
+// WithBearerTokenFromConfig sets the Authorization header to "Bearer <token>", where
+// token is the value of a config key that was read by a previous reader
+func WithBearerTokenFromConfig(key string) func(*httpReader) {
+	return func(r *httpReader) {
+		r.headerKeys["Authorization"] = configHeader{key: key, prefix: "Bearer "}
+	}
+}
+
+// WithClientCertificate makes the http reader authenticate with a TLS client certificate
+func WithClientCertificate(certFile, keyFile string) func(*httpReader) {
+	return func(r *httpReader) {
+		r.certFile = certFile
+		r.keyFile = keyFile
+	}
+}
+
+// WithCACertificate makes the http reader trust the certificate authorities in caFile
+// instead of the system's certificate pool
+func WithCACertificate(caFile string) func(*httpReader) {
+	return func(r *httpReader) {
+		r.caFile = caFile
+	}
+}
+
+// WithRetries makes the http reader retry up to n times on network errors, 429s and 5xx responses.
+// The wait between retries starts at backoff and doubles after each attempt
+func WithRetries(n int, backoff time.Duration) func(*httpReader) {
+	return func(r *httpReader) {
+		r.retries = n
+		r.backoff = backoff
+	}
+}
+
+// WithTimeout sets the timeout of each request of the http reader
+// By default, it is 30 seconds
+func WithTimeout(timeout time.Duration) func(*httpReader) {
+	return func(r *httpReader) {
+		r.timeout = timeout
+	}
+}
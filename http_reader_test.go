@@ -0,0 +1,195 @@
+package conflux
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPReader(t *testing.T) {
+	var requests, failures, notModified atomic.Int32
+	var ifNoneMatch atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		ifNoneMatch.Store(r.Header.Get("If-None-Match"))
+		if failures.Load() > 0 {
+			failures.Add(-1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"ssh_port": 2222, "db": {"host": "db.internal", "tls": true}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	r := NewConfigMux(
+		WithEnvReader(WithEnviron([]string{"CONFIG_SERVICE_TOKEN=abc"})),
+		WithHTTPReader(server.URL, WithBearerTokenFromConfig("config_service_token"), WithRetries(2, time.Millisecond)),
+	)
+
+	expected := map[string]string{"ssh_port": "2222", "db.host": "db.internal", "db.tls": "true"}
+	for _, tc := range []struct {
+		name                string
+		expectedIfNoneMatch string
+		expectedNotModified int32
+	}{
+		{name: "first read", expectedIfNoneMatch: "", expectedNotModified: 0},
+		{name: "cached read", expectedIfNoneMatch: `"v1"`, expectedNotModified: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			configMap := make(map[string]string)
+			if _, err := Unmarshal(r, &configMap); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for k, v := range expected {
+				if configMap[k] != v {
+					t.Errorf("expected %s to be %s, got %s", k, v, configMap[k])
+				}
+			}
+			if ifNoneMatch.Load() != tc.expectedIfNoneMatch {
+				t.Errorf("expected If-None-Match to be %q, got %q", tc.expectedIfNoneMatch, ifNoneMatch.Load())
+			}
+			// the values of a cached read come from the ETag cache, since the server sent no body
+			if notModified.Load() != tc.expectedNotModified {
+				t.Errorf("expected %d 304 responses, got %d", tc.expectedNotModified, notModified.Load())
+			}
+		})
+	}
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		requests.Store(0)
+		failures.Store(2)
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(r, &configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if requests.Load() != 3 {
+			t.Errorf("expected 3 requests, got %d", requests.Load())
+		}
+	})
+
+	t.Run("missing token is reported in diagnostics", func(t *testing.T) {
+		r := NewConfigMux(WithHTTPReader(server.URL, WithBearerTokenFromConfig("config_service_token")))
+		configMap := make(map[string]string)
+		diagnostics, err := Unmarshal(r, &configMap)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diagnostics["config_service_token"] != StatusMissing {
+			t.Errorf("expected config_service_token to be %s: %v", StatusMissing, diagnostics)
+		}
+	})
+}
+
+func TestHTTPReader_ClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "conflux test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "conflux test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clientKeyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ssh_port": "2222"}`))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	certFile, keyFile, serverCAFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"), filepath.Join(dir, "server-ca.pem")
+	for path, block := range map[string]*pem.Block{
+		certFile:     {Type: "CERTIFICATE", Bytes: clientDER},
+		keyFile:      {Type: "EC PRIVATE KEY", Bytes: clientKeyDER},
+		serverCAFile: {Type: "CERTIFICATE", Bytes: server.Certificate().Raw},
+	} {
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	t.Run("with a client certificate", func(t *testing.T) {
+		r := NewHTTPReader(server.URL, WithCACertificate(serverCAFile), WithClientCertificate(certFile, keyFile))
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(r, &configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if configMap["ssh_port"] != "2222" {
+			t.Errorf("expected ssh_port to be 2222, got %v", configMap)
+		}
+	})
+
+	t.Run("without a client certificate", func(t *testing.T) {
+		r := NewHTTPReader(server.URL, WithCACertificate(serverCAFile))
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(r, &configMap); err == nil {
+			t.Fatalf("expected the server to reject the request, got %v", configMap)
+		}
+	})
+
+	t.Run("without the ca of the server", func(t *testing.T) {
+		r := NewHTTPReader(server.URL, WithClientCertificate(certFile, keyFile))
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(r, &configMap); err == nil {
+			t.Fatalf("expected the certificate of the server not to be trusted, got %v", configMap)
+		}
+	})
+}
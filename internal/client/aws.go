@@ -0,0 +1,250 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+type AWSClient struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	endpointURL     string
+	httpClient      *http.Client
+}
+
+// NewAWSClient creates a client for the AWS SSM Parameter Store and Secrets Manager APIs.
+// If endpointURL is not empty, it is used instead of the regional AWS endpoints.
+func NewAWSClient(region, accessKeyID, secretAccessKey, sessionToken, endpointURL string) AWSClient {
+	return AWSClient{
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    sessionToken,
+		endpointURL:     strings.TrimSuffix(endpointURL, "/"),
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ReadParameters reads all parameters under path recursively, decrypting SecureString parameters.
+// A parameter named /app/prod/db/host under the path /app/prod is returned with the key db.host
+func (c AWSClient) ReadParameters(path string) (map[string]string, error) {
+	prefix := "/" + strings.Trim(path, "/")
+
+	m := make(map[string]string)
+	nextToken := ""
+	for {
+		request := map[string]any{
+			"Path":           prefix,
+			"Recursive":      true,
+			"WithDecryption": true,
+		}
+		if nextToken != "" {
+			request["NextToken"] = nextToken
+		}
+
+		var response struct {
+			Parameters []struct {
+				Name  string `json:"Name"`
+				Value string `json:"Value"`
+			} `json:"Parameters"`
+			NextToken string `json:"NextToken"`
+		}
+		if err := c.do("ssm", "AmazonSSM.GetParametersByPath", request, &response); err != nil {
+			return nil, fmt.Errorf("error getting parameters by path: %w", err)
+		}
+
+		for _, parameter := range response.Parameters {
+			key := strings.Trim(strings.TrimPrefix(parameter.Name, prefix), "/")
+			m[strings.ReplaceAll(key, "/", ".")] = parameter.Value
+		}
+
+		if response.NextToken == "" {
+			return m, nil
+		}
+		nextToken = response.NextToken
+	}
+}
+
+// ReadSecret reads a Secrets Manager secret whose value is a JSON object
+// and returns each of its entries as a key-value pair
+func (c AWSClient) ReadSecret(secretID string) (map[string]string, error) {
+	var response struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := c.do("secretsmanager", "secretsmanager.GetSecretValue", map[string]any{"SecretId": secretID}, &response); err != nil {
+		return nil, fmt.Errorf("error getting secret value: %w", err)
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal([]byte(response.SecretString), &data); err != nil {
+		return nil, fmt.Errorf("error unmarshalling secret (%s), expected a JSON object: %w", secretID, err)
+	}
+
+	m := make(map[string]string, len(data))
+	for k, v := range data {
+		if s, ok := v.(string); ok {
+			m[k] = s
+			continue
+		}
+
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding value of secret key %s: %w", k, err)
+		}
+		m[k] = string(encoded)
+	}
+
+	return m, nil
+}
+
+func (c AWSClient) do(service, target string, request, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("error marshalling request: %w", err)
+	}
+
+	endpoint := c.endpointURL
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", service, c.region)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+
+	c.sign(req, service, body, time.Now().UTC())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResponse struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&errResponse)
+		if isAuthStatus(resp.StatusCode) || isAWSAuthError(errResponse.Type) {
+			return fmt.Errorf("%w: status code %d: %s %s", ErrAuthentication, resp.StatusCode, errResponse.Type, errResponse.Message)
+		}
+		return fmt.Errorf("unexpected status code %d: %s %s", resp.StatusCode, errResponse.Type, errResponse.Message)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req
+// the host, content-type and x-amz-* headers are signed
+func (c AWSClient) sign(req *http.Request, service string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if c.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.sessionToken)
+	}
+
+	canonicalRequest, signedHeaders := canonicalRequest(req, body)
+	scope := strings.Join([]string{date, c.region, service, "aws4_request"}, "/")
+	stringToSign := stringToSign(amzDate, scope, canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+c.secretAccessKey), date)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// stringToSign returns the string that is signed for a canonical request
+func stringToSign(amzDate, scope, canonicalRequest string) string {
+	return strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+}
+
+// canonicalRequest returns the canonical request of req and the names of its signed headers
+func canonicalRequest(req *http.Request, body []byte) (string, string) {
+	signedHeaders := []string{"host"}
+	for name := range req.Header {
+		if name = strings.ToLower(name); name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			signedHeaders = append(signedHeaders, name)
+		}
+	}
+	slices.Sort(signedHeaders)
+
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", h, strings.TrimSpace(value))
+	}
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	// query parameters are sorted by name and then by value, and spaces are encoded as %20
+	query := req.URL.Query()
+	for _, values := range query {
+		slices.Sort(values)
+	}
+	canonicalQuery := strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	return strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		hashHex(body),
+	}, "\n"), strings.Join(signedHeaders, ";")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// isAWSAuthError reports whether an AWS error type means that the credentials were rejected
+// some of these are returned with a 400 status code. the type may be prefixed with a namespace, like
+// com.amazon.coral.service#UnrecognizedClientException
+func isAWSAuthError(errType string) bool {
+	switch errType[strings.LastIndex(errType, "#")+1:] {
+	case "UnrecognizedClientException", "InvalidSignatureException", "IncompleteSignature",
+		"InvalidClientTokenId", "ExpiredTokenException", "AccessDeniedException", "MissingAuthenticationToken":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAWSClient_Sign checks the signer against the AWS Signature Version 4 test suite
+// https://docs.aws.amazon.com/general/latest/gr/signature-v4-test-suite.html
+func TestAWSClient_Sign(t *testing.T) {
+	cases := []struct {
+		name                     string
+		method                   string
+		url                      string
+		headers                  map[string]string
+		body                     string
+		expectedCanonicalRequest string
+		expectedStringToSign     string
+		expectedSignature        string
+	}{
+		{
+			name:                     "get-vanilla",
+			method:                   http.MethodGet,
+			url:                      "https://example.amazonaws.com/",
+			expectedCanonicalRequest: "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			expectedStringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\nbb579772317eb040ac9ed261061d46c1f17a8133879d6129b6e1c25292927e63",
+			expectedSignature:        "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:                     "get-vanilla-query-order-key-case",
+			method:                   http.MethodGet,
+			url:                      "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			expectedCanonicalRequest: "GET\n/\nParam1=value1&Param2=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			expectedStringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n816cd5b414d056048ba4f7c5386d6e0533120fb1fcfa93762cf0fc39e2cf19e0",
+			expectedSignature:        "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:                     "post-vanilla",
+			method:                   http.MethodPost,
+			url:                      "https://example.amazonaws.com/",
+			expectedCanonicalRequest: "POST\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			expectedStringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n553f88c9e4d10fc9e109e2aeb65f030801b70c2f6468faca261d401ae622fc87",
+			expectedSignature:        "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:                     "post-x-www-form-urlencoded",
+			method:                   http.MethodPost,
+			url:                      "https://example.amazonaws.com/",
+			headers:                  map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:                     "Param1=value1",
+			expectedCanonicalRequest: "POST\n/\n\ncontent-type:application/x-www-form-urlencoded\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\ncontent-type;host;x-amz-date\n9095672bbd1f56dfc5b65f3e153adc8731a4a654192329106275f4c7b24d0b6e",
+			expectedStringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n42a5e5bb34198acb3e84da4f085bb7927f2bc277ca766e6d19c73c2154021281",
+			expectedSignature:        "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	c := NewAWSClient("us-east-1", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "", "")
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+
+			c.sign(req, "service", []byte(tc.body), now)
+
+			canonical, _ := canonicalRequest(req, []byte(tc.body))
+			if canonical != tc.expectedCanonicalRequest {
+				t.Errorf("expected canonical request:\n%s\ngot:\n%s", tc.expectedCanonicalRequest, canonical)
+			}
+			if stringToSign := stringToSign("20150830T123600Z", "20150830/us-east-1/service/aws4_request", canonical); stringToSign != tc.expectedStringToSign {
+				t.Errorf("expected string to sign:\n%s\ngot:\n%s", tc.expectedStringToSign, stringToSign)
+			}
+
+			signedHeaders := "host;x-amz-date"
+			if len(tc.headers) > 0 {
+				signedHeaders = "content-type;host;x-amz-date"
+			}
+			expectedAuthorization := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=" + signedHeaders + ", Signature=" + tc.expectedSignature
+			if authorization := req.Header.Get("Authorization"); authorization != expectedAuthorization {
+				t.Errorf("expected authorization:\n%s\ngot:\n%s", expectedAuthorization, authorization)
+			}
+		})
+	}
+}
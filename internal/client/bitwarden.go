@@ -6,44 +6,28 @@ import (
 	"github.com/bitwarden/sdk-go"
 )
 
-type BitwardenClient struct {
-	organizationID string
-	client         sdk.BitwardenClientInterface
-}
-
-func NewBitwardenClient(apiURL, identityURL, accessToken, organizationID, stateFile string) (BitwardenClient, error) {
+// NewBitwardenClient creates a Bitwarden SDK client that is logged in with accessToken
+// Secrets are listed and fetched through the SDK client, see conflux.SecretStore
+func NewBitwardenClient(apiURL, identityURL, accessToken, stateFile string) (sdk.BitwardenClientInterface, error) {
 	bitwardenClient, err := sdk.NewBitwardenClient(&apiURL, &identityURL)
 	if err != nil {
-		return BitwardenClient{}, fmt.Errorf("error initializing bitwarden client: %v", err)
+		return nil, fmt.Errorf("error initializing bitwarden client: %w", err)
 	}
 
-	if err := bitwardenClient.AccessTokenLogin(accessToken, &stateFile); err != nil {
-		return BitwardenClient{}, fmt.Errorf("error logging in to bitwarden client: %v", err)
+	if err := bitwardenClient.AccessTokenLogin(accessToken, &stateFile); err != nil && isBitwardenAuthError(err) {
+		return nil, fmt.Errorf("error logging in to bitwarden client: %w: %w", ErrAuthentication, err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error logging in to bitwarden client: %w", err)
 	}
 
-	return BitwardenClient{
-		organizationID: organizationID,
-		client:         bitwardenClient,
-	}, nil
+	return bitwardenClient, nil
 }
 
-func (c BitwardenClient) ReadSecrets() (map[string]string, error) {
-	m := make(map[string]string)
-
-	secrets := c.client.Secrets()
-	listResponse, err := secrets.List(c.organizationID)
-	if err != nil {
-		return nil, fmt.Errorf("error listing secrets: %v", err)
-	}
-
-	for _, secret := range listResponse.Data {
-		secretData, err := secrets.Get(secret.ID)
-		if err != nil {
-			return nil, fmt.Errorf("error getting secret: %v", err)
-		}
-
-		m[secret.Key] = secretData.Value
+// WrapBitwardenError wraps an error of the Bitwarden SDK with ErrAuthentication if it means that
+// the credentials were rejected, like when the access token expired after logging in
+func WrapBitwardenError(err error) error {
+	if err != nil && isBitwardenAuthError(err) {
+		return fmt.Errorf("%w: %w", ErrAuthentication, err)
 	}
-
-	return m, nil
+	return err
 }
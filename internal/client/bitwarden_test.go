@@ -0,0 +1,29 @@
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsBitwardenAuthError(t *testing.T) {
+	cases := []struct {
+		message  string
+		expected bool
+	}{
+		{message: `Received error message from server: [400 Bad Request] {"error":"invalid_client"}`, expected: true},
+		{message: `Received error message from server: [401 Unauthorized] {}`, expected: true},
+		{message: `Received error message from server: [403 Forbidden] {}`, expected: true},
+		{message: `{"error":"invalid_grant","error_description":"access_token is expired"}`, expected: true},
+		{message: "Access token is not in a valid format: Doesn't contain a decryption key", expected: true},
+		{message: `Received error message from server: [500 Internal Server Error] {}`, expected: false},
+		{message: `Received error message from server: [503 Service Unavailable] upstream connect error`, expected: false},
+		{message: "error sending request for url (https://identity.bitwarden.com/connect/token)", expected: false},
+		{message: "dns error: failed to lookup address information", expected: false},
+	}
+
+	for _, tc := range cases {
+		if got := isBitwardenAuthError(errors.New(tc.message)); got != tc.expected {
+			t.Errorf("expected isBitwardenAuthError(%q) to be %t, got %t", tc.message, tc.expected, got)
+		}
+	}
+}
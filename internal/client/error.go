@@ -0,0 +1,37 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrAuthentication is returned when a service rejects the credentials of a client
+var ErrAuthentication = errors.New("authentication failed")
+
+// isAuthStatus reports whether an http status code means that the credentials were rejected
+func isAuthStatus(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
+
+// bitwardenStatusPattern matches the status code in messages like "[400 Bad Request]"
+var bitwardenStatusPattern = regexp.MustCompile(`\[(\d{3})[ \]]`)
+
+// isBitwardenAuthError reports whether an error of the Bitwarden SDK means that the credentials were rejected.
+// The SDK only returns error messages, so this looks for a 400, 401 or 403 status, an OAuth error like
+// invalid_client, or an access token that the SDK couldn't parse. Network errors and server errors like a 500 are not
+func isBitwardenAuthError(err error) bool {
+	message := strings.ToLower(err.Error())
+	if match := bitwardenStatusPattern.FindStringSubmatch(message); match != nil {
+		statusCode, _ := strconv.Atoi(match[1])
+		if statusCode == http.StatusBadRequest || isAuthStatus(statusCode) {
+			return true
+		}
+	}
+	return slices.ContainsFunc([]string{"invalid_client", "invalid_grant", "access token is not in a valid format"}, func(s string) bool {
+		return strings.Contains(message, s)
+	})
+}
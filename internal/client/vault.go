@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// statusError is returned when vault responds with an unexpected status code
+type statusError struct {
+	statusCode int
+	message    string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.statusCode, e.message)
+}
+
+type VaultClient struct {
+	address    string
+	token      string
+	namespace  string
+	httpClient *http.Client
+}
+
+func NewVaultClient(address, token, namespace string) VaultClient {
+	return VaultClient{
+		address:    strings.TrimSuffix(address, "/"),
+		token:      token,
+		namespace:  namespace,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// LoginAppRole exchanges an AppRole role ID and secret ID for a client token
+// and returns a copy of the client which uses that token
+func (c VaultClient) LoginAppRole(roleID, secretID string) (VaultClient, error) {
+	body, err := json.Marshal(map[string]string{"role_id": roleID, "secret_id": secretID})
+	if err != nil {
+		return VaultClient{}, fmt.Errorf("error marshalling approle login body: %w", err)
+	}
+
+	var response struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	var statusErr *statusError
+	if err := c.do(http.MethodPost, "auth/approle/login", body, &response); errors.As(err, &statusErr) && statusErr.statusCode == http.StatusBadRequest {
+		// vault rejects an invalid role ID or secret ID with a 400
+		return VaultClient{}, fmt.Errorf("error logging in with approle: %w: %w", ErrAuthentication, err)
+	} else if err != nil {
+		return VaultClient{}, fmt.Errorf("error logging in with approle: %w", err)
+	}
+
+	if response.Auth.ClientToken == "" {
+		return VaultClient{}, fmt.Errorf("approle login response did not contain a client token")
+	}
+
+	c.token = response.Auth.ClientToken
+	return c, nil
+}
+
+// ReadSecrets reads the secret at path from a KV secrets engine mounted at mount.
+// kvVersion must be either "1" or "2"
+func (c VaultClient) ReadSecrets(mount, path, kvVersion string) (map[string]string, error) {
+	mount, path = strings.Trim(mount, "/"), strings.Trim(path, "/")
+
+	var data map[string]any
+	switch kvVersion {
+	case "1":
+		var response struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := c.do(http.MethodGet, mount+"/"+path, nil, &response); err != nil {
+			return nil, fmt.Errorf("error reading kv v1 secret: %w", err)
+		}
+		data = response.Data
+	case "2":
+		var response struct {
+			Data struct {
+				Data map[string]any `json:"data"`
+			} `json:"data"`
+		}
+		if err := c.do(http.MethodGet, mount+"/data/"+path, nil, &response); err != nil {
+			return nil, fmt.Errorf("error reading kv v2 secret: %w", err)
+		}
+		data = response.Data.Data
+	default:
+		return nil, fmt.Errorf("unsupported kv version: %s", kvVersion)
+	}
+
+	m := make(map[string]string, len(data))
+	for k, v := range data {
+		if s, ok := v.(string); ok {
+			m[k] = s
+			continue
+		}
+
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding value of secret key %s: %w", k, err)
+		}
+		m[k] = string(encoded)
+	}
+
+	return m, nil
+}
+
+func (c VaultClient) do(method, path string, body []byte, target any) error {
+	req, err := http.NewRequest(method, c.address+"/v1/"+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	if c.token != "" {
+		req.Header.Set("X-Vault-Token", c.token)
+	}
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResponse struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&errResponse)
+		if isAuthStatus(resp.StatusCode) {
+			return fmt.Errorf("%w: status code %d: %s", ErrAuthentication, resp.StatusCode, strings.Join(errResponse.Errors, "; "))
+		}
+		return &statusError{statusCode: resp.StatusCode, message: strings.Join(errResponse.Errors, "; ")}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultClient_LoginAppRole(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		expected   bool
+	}{
+		{name: "bad request", statusCode: http.StatusBadRequest, expected: true},
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, expected: true},
+		{name: "forbidden", statusCode: http.StatusForbidden, expected: true},
+		{name: "internal server error", statusCode: http.StatusInternalServerError, expected: false},
+		{name: "service unavailable", statusCode: http.StatusServiceUnavailable, expected: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.statusCode)
+				w.Write([]byte(`{"errors":["login failed"]}`))
+			}))
+			defer server.Close()
+
+			_, err := NewVaultClient(server.URL, "", "").LoginAppRole("role", "secret")
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if got := errors.Is(err, ErrAuthentication); got != tc.expected {
+				t.Errorf("expected errors.Is(err, ErrAuthentication) to be %t, got %t: %v", tc.expected, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package conflux
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NameMapper converts a name into a naming convention, like snake_case.
+// It is used to match struct fields without a `conflux` or `json` tag, so that SSHPort matches ssh_port,
+// and to convert the keys of readers, so that SSH_PORT, ssh-port and sshPort are all read as ssh_port.
+// Names are split into words at underscores, dashes, spaces and changes of case,
+// and dots are kept, so db.maxConns becomes db.max_conns with SnakeCase
+type NameMapper func(name string) string
+
+var (
+	// SnakeCase maps SSHPort to ssh_port
+	SnakeCase NameMapper = func(name string) string {
+		return mapSegments(name, func(words []string) string { return strings.ToLower(strings.Join(words, "_")) })
+	}
+	// KebabCase maps SSHPort to ssh-port
+	KebabCase NameMapper = func(name string) string {
+		return mapSegments(name, func(words []string) string { return strings.ToLower(strings.Join(words, "-")) })
+	}
+	// ScreamingSnakeCase maps SSHPort to SSH_PORT, like an environment variable
+	ScreamingSnakeCase NameMapper = func(name string) string {
+		return mapSegments(name, func(words []string) string { return strings.ToUpper(strings.Join(words, "_")) })
+	}
+	// CamelCase maps SSHPort to sshPort, like a key of a JSON document
+	CamelCase NameMapper = func(name string) string {
+		return mapSegments(name, func(words []string) string {
+			for i, word := range words {
+				word = strings.ToLower(word)
+				if i > 0 && word != "" {
+					word = strings.ToUpper(word[:1]) + word[1:]
+				}
+				words[i] = word
+			}
+			return strings.Join(words, "")
+		})
+	}
+)
+
+// mapSegments splits each dot-separated segment of name into words and joins them with join
+func mapSegments(name string, join func(words []string) string) string {
+	segments := strings.Split(name, ".")
+	for i, segment := range segments {
+		segments[i] = join(splitWords(segment))
+	}
+	return strings.Join(segments, ".")
+}
+
+// splitWords splits a name into words at underscores, dashes, spaces and changes of case
+// an acronym is kept as one word, so SSHPort is split into SSH and Port
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+
+		if i > start && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// fieldKey returns the config key of a struct field: its `conflux` tag, its `json` tag,
+// or its name converted with mapper. Without a mapper, it is the name of the field
+func fieldKey(field reflect.StructField, mapper NameMapper) string {
+	if mapper != nil && field.Tag.Get("conflux") == "" && field.Tag.Get("json") == "" {
+		return mapper(field.Name)
+	}
+	return queryForTags(field, "conflux", []string{"json"})
+}
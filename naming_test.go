@@ -0,0 +1,146 @@
+package conflux
+
+import (
+	"maps"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNameMappers(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected map[string]string
+	}{
+		{name: "SSHPort", expected: map[string]string{"snake": "ssh_port", "kebab": "ssh-port", "screaming": "SSH_PORT", "camel": "sshPort"}},
+		{name: "ssh_port", expected: map[string]string{"snake": "ssh_port", "kebab": "ssh-port", "screaming": "SSH_PORT", "camel": "sshPort"}},
+		{name: "SSH_PORT", expected: map[string]string{"snake": "ssh_port", "kebab": "ssh-port", "screaming": "SSH_PORT", "camel": "sshPort"}},
+		{name: "ssh-port", expected: map[string]string{"snake": "ssh_port", "kebab": "ssh-port", "screaming": "SSH_PORT", "camel": "sshPort"}},
+		{name: "sshPort", expected: map[string]string{"snake": "ssh_port", "kebab": "ssh-port", "screaming": "SSH_PORT", "camel": "sshPort"}},
+		{name: "HTTPServerURL", expected: map[string]string{"snake": "http_server_url", "kebab": "http-server-url", "screaming": "HTTP_SERVER_URL", "camel": "httpServerUrl"}},
+		{name: "Port2FA", expected: map[string]string{"snake": "port2_fa", "kebab": "port2-fa", "screaming": "PORT2_FA", "camel": "port2Fa"}},
+		{name: "db.maxConns", expected: map[string]string{"snake": "db.max_conns", "kebab": "db.max-conns", "screaming": "DB.MAX_CONNS", "camel": "db.maxConns"}},
+	}
+
+	mappers := map[string]NameMapper{"snake": SnakeCase, "kebab": KebabCase, "screaming": ScreamingSnakeCase, "camel": CamelCase}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for mapperName, mapper := range mappers {
+				if actual := mapper(tc.name); actual != tc.expected[mapperName] {
+					t.Errorf("expected %s to map %s to %s, got %s", mapperName, tc.name, tc.expected[mapperName], actual)
+				}
+			}
+		})
+	}
+}
+
+type untaggedConfig struct {
+	SSHPort     string `required:"true"`
+	GatewayAddr string `required:"true"`
+	PhysicalNIC string `json:"nic" required:"true"`
+}
+
+func TestConfigMux_NameMapper(t *testing.T) {
+	newOpts := func() []func(*ConfigMux) {
+		return []func(*ConfigMux){
+			WithYAMLFileReader("config/all.yml", WithFileSystem(fstest.MapFS{
+				"config/all.yml": {Data: []byte("ssh-port: 22\nnic: eth0\n")},
+			})),
+			WithEnvReader(WithEnviron([]string{"GATEWAY_ADDR=10.0.0.1"})),
+		}
+	}
+
+	t.Run("global", func(t *testing.T) {
+		target := untaggedConfig{}
+		result, err := NewConfigMux(append(newOpts(), WithNameMapper(SnakeCase))...).ReadSourced()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		diagnostics, err := Unmarshal(result, &target)
+		if err != nil {
+			t.Fatalf("unexpected error: %v, diagnostics: %v", err, diagnostics)
+		}
+
+		expected := untaggedConfig{SSHPort: "22", GatewayAddr: "10.0.0.1", PhysicalNIC: "eth0"}
+		if target != expected {
+			t.Errorf("expected %+v, got %+v", expected, target)
+		}
+		if diagnostics["ssh_port"] != StatusLoaded {
+			t.Errorf("expected diagnostics[\"ssh_port\"] to be %q, got %v", StatusLoaded, diagnostics)
+		}
+		if values := result.Explain("SSH_PORT"); len(values) != 1 || values[0].Value != "22" {
+			t.Errorf("expected ssh_port to be explained, got %v", values)
+		}
+	})
+
+	t.Run("per reader", func(t *testing.T) {
+		configMux := NewConfigMux(
+			WithReaderNameMapper(SnakeCase, newOpts()[0]),
+			newOpts()[1],
+		)
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(configMux, &configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if configMap["ssh_port"] != "22" || configMap["gateway_addr"] != "10.0.0.1" {
+			t.Errorf("expected the keys of the yaml reader to be converted, got %v", configMap)
+		}
+	})
+
+	t.Run("flags and the bitwarden target", func(t *testing.T) {
+		type secretConfig struct {
+			SSHPort    string `required:"true"`
+			DBPassword string `required:"true"`
+		}
+		store := newFakeSecretStore(1, 0)
+		store.secrets = append(store.secrets, SecretValue{ID: "id-db", Key: "db-password", Value: "hunter2"})
+
+		target := secretConfig{}
+		configMux := NewConfigMux(
+			WithEnvReader(WithEnviron([]string{"BITWARDEN_ACCESS_TOKEN=valid", "BITWARDEN_ORGANIZATION_ID=org"})),
+			WithBitwardenSecretReader(WithBitwardenClientFactory(store.factory), WithBitwardenTarget(&target)),
+			WithFlagReader([]string{"--ssh-port", "2222"}, WithFlagTarget(&target)),
+			WithNameMapper(SnakeCase),
+		)
+		configMap := make(map[string]string)
+		if _, err := Unmarshal(configMux, &configMap); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := map[string]string{"bitwarden_access_token": "valid", "bitwarden_organization_id": "org", "db_password": "hunter2", "ssh_port": "2222"}
+		if !maps.Equal(configMap, expected) {
+			t.Errorf("expected %v, got %v", expected, configMap)
+		}
+	})
+
+	t.Run("dump, export and examples", func(t *testing.T) {
+		target := untaggedConfig{SSHPort: "22", GatewayAddr: "10.0.0.1", PhysicalNIC: "eth0"}
+
+		dump, err := Dump(&target, DumpOptions{Format: DumpFormatJSON, NameMapper: SnakeCase})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		export, err := Export(&target, ExportOptions{Format: ExportFormatJSON, NameMapper: SnakeCase})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		example, err := GenerateExampleYAML(&target, SnakeCase)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for name, out := range map[string]string{"dump": dump, "export": export, "example": example} {
+			if !strings.Contains(out, "ssh_port") || !strings.Contains(out, "gateway_addr") || strings.Contains(out, "SSHPort") {
+				t.Errorf("expected %s to have the converted keys, got:\n%s", name, out)
+			}
+		}
+	})
+
+	t.Run("without a mapper", func(t *testing.T) {
+		target := untaggedConfig{}
+		if _, err := Unmarshal(NewConfigMux(newOpts()...), &target); err == nil {
+			t.Errorf("expected untagged fields not to match, got %+v", target)
+		}
+	})
+}
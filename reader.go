@@ -1,5 +1,11 @@
 package conflux
 
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
 // Reader is the interface that must be implemented
 // if you want to define your own source of reading
 // configuration data
@@ -62,3 +68,51 @@ func (r DiagnosticReadResult) readResult() {}
 func (r DiagnosticReadResult) GetConfigMap() map[string]string {
 	return r.configMap
 }
+
+// SourcedReadResult is what a ConfigMux returns from Read. Along with the config and its diagnostics,
+// it has the source of every value, so that each call to Read has its own.
+// It is also a Reader that returns itself, so it can be passed to Unmarshal, see ConfigMux.ReadSourced
+type SourcedReadResult struct {
+	configMap         map[string]string
+	diagnostics       map[string]string
+	sources           map[string]Source
+	diagnosticSources map[string]string
+	history           map[string][]SourcedValue
+	nameMapper        NameMapper
+}
+
+func (r SourcedReadResult) readResult() {}
+
+func (r SourcedReadResult) GetConfigMap() map[string]string {
+	return r.configMap
+}
+
+func (r SourcedReadResult) Read() (ReadResult, error) {
+	return r, nil
+}
+
+// Sources returns the source of each config value
+// Keys are lowercase, and each source is the reader with the highest priority
+// that had a value for that key
+func (r SourcedReadResult) Sources() map[string]Source {
+	return maps.Clone(r.sources)
+}
+
+// DiagnosticSources returns the name of the reader that reported each diagnostic
+// This can be used to group diagnostics by reader, for example with TableRenderer
+func (r SourcedReadResult) DiagnosticSources() map[string]string {
+	return maps.Clone(r.diagnosticSources)
+}
+
+// Explain returns every value that the readers had for key, from lowest to highest priority
+// The last value is the one that was used
+func (r SourcedReadResult) Explain(key string) []SourcedValue {
+	if r.nameMapper != nil {
+		key = r.nameMapper(key)
+	}
+	return slices.Clone(r.history[strings.ToLower(key)])
+}
+
+func (r SourcedReadResult) fieldNameMapper() NameMapper {
+	return r.nameMapper
+}
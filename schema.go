@@ -0,0 +1,65 @@
+package conflux
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonSchemaDraft is the JSON Schema draft of the schemas returned by GenerateJSONSchema
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+type jsonSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Type        any                    `json:"type"`
+	Description string                 `json:"description,omitempty"`
+	Default     string                 `json:"default,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	WriteOnly   bool                   `json:"writeOnly,omitempty"`
+	Properties  map[string]*jsonSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+}
+
+// GenerateJSONSchema returns a JSON Schema of the config files that can fill target, so that
+// editors and CI can validate yaml configs before they are read.
+// Properties have the same keys that Unmarshal matches, descriptions come from the `desc` tag,
+// enums come from the `enum` tag, and defaults are the values of the fields of target.
+// Since yaml scalars like 22 or true are read as strings, properties also accept numbers and booleans,
+// unless they have an enum.
+// Other keys are allowed, because a config file usually also has the keys of other structs.
+// See DescribeKeys for mapper
+func GenerateJSONSchema(target any, mapper NameMapper) ([]byte, error) {
+	descriptions, err := DescribeKeys(target, mapper)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := jsonSchema{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: make(map[string]*jsonSchema, len(descriptions)),
+	}
+	for _, d := range descriptions {
+		property := &jsonSchema{
+			Type:        []string{"string", "number", "boolean"},
+			Description: d.Description,
+			Default:     d.Default,
+			Enum:        d.Enum,
+			WriteOnly:   d.Type == "secret",
+		}
+		if d.Enum != nil {
+			property.Type = "string"
+		}
+
+		schema.Properties[d.Key] = property
+		if d.Required {
+			schema.Required = append(schema.Required, d.Key)
+		}
+	}
+
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling json schema: %w", err)
+	}
+
+	return append(out, '\n'), nil
+}
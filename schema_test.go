@@ -0,0 +1,75 @@
+package conflux
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type schemaConfig struct {
+	SSHPort    string `json:"ssh_port" required:"true" desc:"port of the SSH server"`
+	LogLevel   string `json:"log_level" enum:"debug, info, warn" desc:"verbosity of the logs"`
+	DBPassword Secret `json:"db_password" required:"true"`
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	out, err := GenerateJSONSchema(&schemaConfig{LogLevel: "info"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var schema jsonSchema
+	if err := json.Unmarshal(out, &schema); err != nil {
+		t.Fatalf("unexpected error unmarshalling schema: %v", err)
+	}
+
+	if schema.Schema != jsonSchemaDraft || schema.Type != "object" {
+		t.Errorf("expected an object schema of draft %s, got %s %v", jsonSchemaDraft, schema.Schema, schema.Type)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"ssh_port", "db_password"}) {
+		t.Errorf("expected ssh_port and db_password to be required, got %v", schema.Required)
+	}
+
+	expected := map[string]jsonSchema{
+		"ssh_port":    {Type: []any{"string", "number", "boolean"}, Description: "port of the SSH server"},
+		"log_level":   {Type: "string", Description: "verbosity of the logs", Default: "info", Enum: []string{"debug", "info", "warn"}},
+		"db_password": {Type: []any{"string", "number", "boolean"}, WriteOnly: true},
+	}
+	if len(schema.Properties) != len(expected) {
+		t.Fatalf("expected %d properties, got %d", len(expected), len(schema.Properties))
+	}
+	for key, expectedProperty := range expected {
+		if property := schema.Properties[key]; property == nil || !reflect.DeepEqual(*property, expectedProperty) {
+			t.Errorf("expected property %s to be %+v, got %+v", key, expectedProperty, property)
+		}
+	}
+}
+
+func TestUnmarshal_Enum(t *testing.T) {
+	cases := []struct {
+		name        string
+		logLevel    string
+		expectedErr error
+	}{
+		{name: "allowed value", logLevel: "warn"},
+		{name: "empty value is not checked", logLevel: ""},
+		{name: "value outside of enum", logLevel: "verbose", expectedErr: ErrInvalidFields},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newMapReader(map[string]string{"ssh_port": "22", "db_password": "hunter2", "log_level": tc.logLevel})
+
+			var target schemaConfig
+			diagnostics, err := Unmarshal(r, &target)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil && !strings.HasPrefix(diagnostics["log_level"], StatusInvalid) {
+				t.Errorf("expected log_level to be invalid, got %q", diagnostics["log_level"])
+			}
+		})
+	}
+}
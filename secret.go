@@ -0,0 +1,55 @@
+package conflux
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret is a string that is redacted whenever it is printed, logged or marshalled.
+// It can be used as the type of a struct field that is filled by Unmarshal.
+// The only way to get its value is with Reveal.
+// If you'd rather keep a field as a plain string, you can tag it with `secret:"true"`
+// instead, so that conflux redacts it in its own output
+type Secret struct {
+	value string
+}
+
+// NewSecret creates a new Secret with the given value
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Reveal returns the value of the secret
+func (s Secret) Reveal() string {
+	return s.value
+}
+
+func (s Secret) String() string {
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return redacted
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+var secretType = reflect.TypeFor[Secret]()
+
+// isSecretField reports whether a struct field is a Secret or is tagged with `secret:"true"`
+func isSecretField(field reflect.StructField) bool {
+	return field.Type == secretType || field.Tag.Get("secret") == "true"
+}
@@ -0,0 +1,248 @@
+package conflux
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitwarden/sdk-go"
+	"github.com/dannyvelas/conflux/internal/client"
+)
+
+// SecretStore is the interface that a secret manager like Bitwarden must implement
+// so that it can be read by the Bitwarden secret reader.
+// This is useful to replace Bitwarden with an in-memory fake in tests,
+// or with an alternate backend. See WithBitwardenClientFactory
+type SecretStore interface {
+	// List returns the identifiers of all the secrets that can be read
+	List() ([]SecretIdentifier, error)
+	// Get returns the secrets with the given IDs
+	Get(ids []string) ([]SecretValue, error)
+}
+
+// SecretIdentifier is the ID and key of a secret, without its value
+type SecretIdentifier struct {
+	ID  string
+	Key string
+}
+
+// SecretValue is a secret with its value
+type SecretValue struct {
+	ID        string
+	Key       string
+	Value     string
+	ProjectID string
+}
+
+// BitwardenCredentials are the values that are used to authenticate to Bitwarden
+// They are read from the bitwarden_* config keys
+type BitwardenCredentials struct {
+	APIURL         string
+	IdentityURL    string
+	AccessToken    string
+	OrganizationID string
+	StateFilePath  string
+}
+
+var _ SecretStore = bitwardenStore{}
+
+// bitwardenStore adapts a Bitwarden SDK client to the SecretStore interface
+type bitwardenStore struct {
+	client         sdk.BitwardenClientInterface
+	organizationID string
+}
+
+func newBitwardenStore(creds BitwardenCredentials) (SecretStore, error) {
+	bitwardenClient, err := client.NewBitwardenClient(
+		creds.APIURL,
+		creds.IdentityURL,
+		creds.AccessToken,
+		creds.StateFilePath,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return bitwardenStore{client: bitwardenClient, organizationID: creds.OrganizationID}, nil
+}
+
+// List returns the identifiers of all the secrets in the organization
+func (s bitwardenStore) List() ([]SecretIdentifier, error) {
+	response, err := s.client.Secrets().List(s.organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing secrets: %w", client.WrapBitwardenError(err))
+	}
+
+	identifiers := make([]SecretIdentifier, 0, len(response.Data))
+	for _, secret := range response.Data {
+		identifiers = append(identifiers, SecretIdentifier{ID: secret.ID, Key: secret.Key})
+	}
+	return identifiers, nil
+}
+
+// Get returns the secrets with the given IDs using a single request
+func (s bitwardenStore) Get(ids []string) ([]SecretValue, error) {
+	response, err := s.client.Secrets().GetByIDS(ids)
+	if err != nil {
+		return nil, fmt.Errorf("error getting secrets: %w", client.WrapBitwardenError(err))
+	}
+
+	secrets := make([]SecretValue, 0, len(response.Data))
+	for _, secret := range response.Data {
+		projectID := ""
+		if secret.ProjectID != nil {
+			projectID = *secret.ProjectID
+		}
+		secrets = append(secrets, SecretValue{ID: secret.ID, Key: secret.Key, Value: secret.Value, ProjectID: projectID})
+	}
+	return secrets, nil
+}
+
+// secretFilter limits which secrets are read from a SecretStore
+// Zero values don't filter anything
+type secretFilter struct {
+	// projectIDs keeps only secrets that belong to one of these projects.
+	// Unlike the other filters, it is applied after the secrets are fetched
+	projectIDs []string
+	// keyPrefix keeps only secrets whose key starts with this prefix.
+	// The prefix is stripped from the keys that are returned
+	keyPrefix string
+	// keys keeps only secrets whose key (after stripping keyPrefix) is in this list.
+	// Keys are compared case-insensitively, and with the key converted by mapper, if any
+	keys   []string
+	mapper NameMapper
+}
+
+// matchKey reports whether key passes the filter, and returns it without keyPrefix
+func (f secretFilter) matchKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, f.keyPrefix) {
+		return "", false
+	}
+	key = strings.TrimPrefix(key, f.keyPrefix)
+
+	if len(f.keys) > 0 && !slices.ContainsFunc(f.keys, func(k string) bool {
+		return strings.EqualFold(k, key) || (f.mapper != nil && strings.EqualFold(k, f.mapper(key)))
+	}) {
+		return "", false
+	}
+
+	return key, true
+}
+
+func (f secretFilter) matchProject(projectID string) bool {
+	return len(f.projectIDs) == 0 || slices.Contains(f.projectIDs, projectID)
+}
+
+// fetchOptions controls how secrets are fetched from a SecretStore
+type fetchOptions struct {
+	// batchSize is the number of secrets that are fetched by each Get call
+	batchSize int
+	// concurrency is the number of batches that are fetched at the same time
+	concurrency int
+	// retries is the number of times that a failed batch is retried.
+	// Bitwarden doesn't tell transient errors apart from permanent ones, so every error is retried,
+	// except for errors that match ErrAuthentication, since rejected credentials won't be accepted on a retry
+	retries int
+	// backoff is the wait before the first retry. It doubles after each retry
+	backoff time.Duration
+}
+
+func newFetchOptions() fetchOptions {
+	return fetchOptions{
+		batchSize:   50,
+		concurrency: 4,
+		retries:     2,
+		backoff:     500 * time.Millisecond,
+	}
+}
+
+// readSecretStore reads all the secrets of store that pass filter
+func readSecretStore(store SecretStore, filter secretFilter, opts fetchOptions) (map[string]string, error) {
+	identifiers, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("error listing secrets: %w", err)
+	}
+
+	// keys are stripped of their prefix here, because the responses of Get have unstripped keys
+	idToKey := make(map[string]string)
+	ids := make([]string, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		key, ok := filter.matchKey(identifier.Key)
+		if !ok {
+			continue
+		}
+		idToKey[identifier.ID] = key
+		ids = append(ids, identifier.ID)
+	}
+
+	secrets, err := fetchSecrets(store, ids, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error getting secrets: %w", err)
+	}
+
+	// projects can only be filtered here, because List doesn't return the project of a secret,
+	// so the secrets of other projects that matched the keys above were fetched too
+	m := make(map[string]string, len(secrets))
+	for _, secret := range secrets {
+		if !filter.matchProject(secret.ProjectID) {
+			continue
+		}
+
+		m[idToKey[secret.ID]] = secret.Value
+	}
+
+	return m, nil
+}
+
+// fetchSecrets gets the secrets with the given ids in batches, fetching up to
+// opts.concurrency batches at the same time
+func fetchSecrets(store SecretStore, ids []string, opts fetchOptions) ([]SecretValue, error) {
+	batches := slices.Collect(slices.Chunk(ids, max(opts.batchSize, 1)))
+	results := make([][]SecretValue, len(batches))
+	errs := make([]error, len(batches))
+
+	semaphore := make(chan struct{}, max(opts.concurrency, 1))
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			results[i], errs[i] = fetchBatch(store, batch, opts)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return slices.Concat(results...), nil
+}
+
+func fetchBatch(store SecretStore, ids []string, opts fetchOptions) ([]SecretValue, error) {
+	var err error
+	attempt := 0
+	for ; attempt <= opts.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(opts.backoff * time.Duration(1<<(attempt-1)))
+		}
+
+		var secrets []SecretValue
+		secrets, err = store.Get(ids)
+		if err == nil {
+			return secrets, nil
+		}
+		if errors.Is(err, ErrAuthentication) {
+			attempt++
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("error getting batch of %d secrets after %d attempts: %w", len(ids), attempt, err)
+}
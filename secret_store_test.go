@@ -0,0 +1,141 @@
+package conflux
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bitwarden/sdk-go"
+)
+
+var _ sdk.BitwardenClientInterface = (*fakeSDKClient)(nil)
+
+// fakeSDKClient is an in-memory sdk.BitwardenClientInterface
+// every call sleeps for latency to simulate a network round-trip
+type fakeSDKClient struct {
+	secrets *fakeSDKSecrets
+}
+
+func (c *fakeSDKClient) AccessTokenLogin(string, *string) error { return nil }
+func (c *fakeSDKClient) Projects() sdk.ProjectsInterface        { return nil }
+func (c *fakeSDKClient) Secrets() sdk.SecretsInterface          { return c.secrets }
+func (c *fakeSDKClient) Generators() sdk.GeneratorsInterface    { return nil }
+func (c *fakeSDKClient) Close()                                 {}
+
+type fakeSDKSecrets struct {
+	sdk.SecretsInterface
+	data    []sdk.SecretResponse
+	latency time.Duration
+}
+
+func newFakeSDKClient(n int, latency time.Duration) *fakeSDKClient {
+	secrets := &fakeSDKSecrets{latency: latency}
+	for i := range n {
+		projectID := fmt.Sprintf("project-%d", i)
+		secrets.data = append(secrets.data, sdk.SecretResponse{
+			ID:        fmt.Sprintf("id-%d", i),
+			Key:       fmt.Sprintf("key_%d", i),
+			Value:     fmt.Sprintf("value-%d", i),
+			ProjectID: &projectID,
+		})
+	}
+	return &fakeSDKClient{secrets: secrets}
+}
+
+func (s *fakeSDKSecrets) List(string) (*sdk.SecretIdentifiersResponse, error) {
+	time.Sleep(s.latency)
+	response := &sdk.SecretIdentifiersResponse{}
+	for _, secret := range s.data {
+		response.Data = append(response.Data, sdk.SecretIdentifierResponse{ID: secret.ID, Key: secret.Key})
+	}
+	return response, nil
+}
+
+func (s *fakeSDKSecrets) GetByIDS(ids []string) (*sdk.SecretsResponse, error) {
+	time.Sleep(s.latency)
+	response := &sdk.SecretsResponse{}
+	for _, id := range ids {
+		for _, secret := range s.data {
+			if secret.ID == id {
+				response.Data = append(response.Data, secret)
+			}
+		}
+	}
+	return response, nil
+}
+
+func TestBitwardenStore(t *testing.T) {
+	store := bitwardenStore{client: newFakeSDKClient(3, 0), organizationID: "org"}
+
+	identifiers, err := store.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(identifiers) != 3 || identifiers[1] != (SecretIdentifier{ID: "id-1", Key: "key_1"}) {
+		t.Fatalf("unexpected identifiers: %v", identifiers)
+	}
+
+	secrets, err := store.Get([]string{"id-0", "id-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []SecretValue{
+		{ID: "id-0", Key: "key_0", Value: "value-0", ProjectID: "project-0"},
+		{ID: "id-2", Key: "key_2", Value: "value-2", ProjectID: "project-2"},
+	}
+	if len(secrets) != len(expected) || secrets[0] != expected[0] || secrets[1] != expected[1] {
+		t.Fatalf("expected %v, got %v", expected, secrets)
+	}
+}
+
+func BenchmarkReadSecretStore(b *testing.B) {
+	cases := []struct {
+		name         string
+		fetchOptions fetchOptions
+	}{
+		{name: "one secret per request", fetchOptions: fetchOptions{batchSize: 1, concurrency: 1}},
+		{name: "default", fetchOptions: newFetchOptions()},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			store := bitwardenStore{client: newFakeSDKClient(200, time.Millisecond), organizationID: "org"}
+			for b.Loop() {
+				if _, err := readSecretStore(store, secretFilter{}, tc.fetchOptions); err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchBatch_Retries(t *testing.T) {
+	cases := []struct {
+		name         string
+		failure      error
+		expectedGets int32
+	}{
+		{name: "transient errors are retried", expectedGets: 3},
+		{name: "rejected credentials are not retried", failure: fmt.Errorf("%w: access token expired", ErrAuthentication), expectedGets: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeSecretStore(3, 0)
+			store.failures.Store(3)
+			store.failure = tc.failure
+
+			_, err := fetchBatch(store, []string{"id-0"}, fetchOptions{retries: 2, backoff: time.Millisecond})
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if tc.failure != nil && !errors.Is(err, ErrAuthentication) {
+				t.Errorf("expected error to match ErrAuthentication, got %v", err)
+			}
+			if gets := store.gets.Load(); gets != tc.expectedGets {
+				t.Errorf("expected Get to be called %d times, got %d", tc.expectedGets, gets)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package conflux
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type secretConfig struct {
+	Username string `json:"username"`
+	Password Secret `json:"password" required:"true"`
+}
+
+func TestSecret(t *testing.T) {
+	r := newMapReader(map[string]string{"username": "admin", "password": "hunter2"})
+
+	target := secretConfig{}
+	if _, err := Unmarshal(r, &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if target.Password.Reveal() != "hunter2" {
+		t.Fatalf("expected password to be hunter2, got %s", target.Password.Reveal())
+	}
+
+	jsonOutput, err := json.Marshal(target)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var logOutput bytes.Buffer
+	slog.New(slog.NewTextHandler(&logOutput, nil)).Info("config", "password", target.Password)
+
+	outputs := map[string]string{
+		"%v":   fmt.Sprintf("%v", target),
+		"%+v":  fmt.Sprintf("%+v", target),
+		"%#v":  fmt.Sprintf("%#v", target),
+		"%s":   fmt.Sprintf("%s", target.Password),
+		"json": string(jsonOutput),
+		"slog": logOutput.String(),
+	}
+	for name, output := range outputs {
+		if strings.Contains(output, "hunter2") || !strings.Contains(output, redacted) {
+			t.Errorf("expected %s output to be redacted, got %s", name, output)
+		}
+	}
+}
+
+func TestSecret_Missing(t *testing.T) {
+	r := newMapReader(map[string]string{"username": "admin"})
+
+	target := secretConfig{}
+	diagnostics, err := Unmarshal(r, &target)
+	if !errors.Is(err, ErrInvalidFields) {
+		t.Fatalf("expected error to be %v, got %v", ErrInvalidFields, err)
+	}
+	if diagnostics["password"] != StatusMissing {
+		t.Errorf("expected password to be %s: %v", StatusMissing, diagnostics)
+	}
+}
@@ -0,0 +1,36 @@
+package conflux
+
+type vaultConfig struct {
+	Address   string `json:"vault_address" required:"true"`
+	Token     string `json:"vault_token"`
+	RoleID    string `json:"vault_role_id"`
+	SecretID  string `json:"vault_secret_id"`
+	Namespace string `json:"vault_namespace"`
+	Mount     string `json:"vault_mount"`
+	Path      string `json:"vault_path" required:"true"`
+	KVVersion string `json:"vault_kv_version"`
+}
+
+func newVaultConfig() vaultConfig {
+	return vaultConfig{
+		Mount:     "secret",
+		KVVersion: "2",
+	}
+}
+
+// Validate makes sure that there are enough credentials to authenticate to Vault,
+// either with a token or with an AppRole role ID and secret ID
+func (c *vaultConfig) Validate(diagnostics map[string]string) bool {
+	if c.Token != "" || (c.RoleID != "" && c.SecretID != "") {
+		return true
+	}
+
+	diagnostics["vault_token"] = StatusMissing
+	if c.RoleID == "" {
+		diagnostics["vault_role_id"] = StatusMissing
+	}
+	if c.SecretID == "" {
+		diagnostics["vault_secret_id"] = StatusMissing
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package conflux
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dannyvelas/conflux/internal/client"
+)
+
+var _ Reader = (*vaultSecretReader)(nil)
+
+type vaultSecretReader struct {
+	mapReader mapReader
+}
+
+// NewVaultSecretReader creates a new HashiCorp Vault secret reader using the provided config map to authenticate to Vault.
+// It authenticates with vault_token if it is present. Otherwise, it uses AppRole with vault_role_id and vault_secret_id.
+func NewVaultSecretReader(configMap map[string]string) *vaultSecretReader {
+	return &vaultSecretReader{
+		mapReader: newMapReader(configMap),
+	}
+}
+
+func (r *vaultSecretReader) Read() (ReadResult, error) {
+	config := newVaultConfig()
+
+	diagnostics, err := Unmarshal(r.mapReader, &config)
+	if errors.Is(err, ErrInvalidFields) {
+		return NewDiagnosticReadResult(nil, diagnostics), ErrInvalidFields
+	} else if err != nil {
+		return nil, fmt.Errorf("error unmarshalling vault creds: %w", err)
+	}
+
+	vaultClient := client.NewVaultClient(config.Address, config.Token, config.Namespace)
+	if config.Token == "" {
+		vaultClient, err = vaultClient.LoginAppRole(config.RoleID, config.SecretID)
+		if err != nil {
+			return nil, &ReaderError{Reader: "vault", Source: config.Address, Err: fmt.Errorf("error authenticating to vault: %w", err)}
+		}
+	}
+
+	vaultSecrets, err := vaultClient.ReadSecrets(config.Mount, config.Path, config.KVVersion)
+	if err != nil {
+		return nil, &ReaderError{Reader: "vault", Source: config.Address, Err: fmt.Errorf("error reading vault secrets: %w", err)}
+	}
+
+	return NewDiagnosticReadResult(vaultSecrets, diagnostics), nil
+}
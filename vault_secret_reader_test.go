@@ -0,0 +1,108 @@
+package conflux
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newVaultTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["role_id"] != "role" || body["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"auth":{"client_token":"approle-token"}}`))
+	})
+	mux.HandleFunc("GET /v1/secret/data/app/prod", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"ssh_port":"2222","gateway_address":"10.0.0.1"}}}`))
+	})
+	mux.HandleFunc("GET /v1/kv/app/prod", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "approle-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"ssh_port":"3333","gateway_address":"10.0.0.2"}}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultSecretReader(t *testing.T) {
+	server := newVaultTestServer(t)
+
+	cases := []struct {
+		name     string
+		env      []string
+		expected map[string]string
+	}{
+		{
+			name:     "kv v2 with token",
+			env:      []string{"VAULT_ADDRESS=" + server.URL, "VAULT_TOKEN=root", "VAULT_PATH=app/prod"},
+			expected: map[string]string{"ssh_port": "2222", "gateway_address": "10.0.0.1"},
+		},
+		{
+			name:     "kv v1 with approle",
+			env:      []string{"VAULT_ADDRESS=" + server.URL, "VAULT_ROLE_ID=role", "VAULT_SECRET_ID=secret", "VAULT_MOUNT=kv", "VAULT_KV_VERSION=1", "VAULT_PATH=app/prod"},
+			expected: map[string]string{"ssh_port": "3333", "gateway_address": "10.0.0.2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewConfigMux(
+				WithEnvReader(WithEnviron(tc.env)),
+				WithVaultReader(),
+			)
+
+			configMap := make(map[string]string)
+			if _, err := Unmarshal(r, &configMap); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for k, v := range tc.expected {
+				if configMap[k] != v {
+					t.Errorf("expected %s to be %s, got %s", k, v, configMap[k])
+				}
+			}
+		})
+	}
+}
+
+func TestVaultSecretReader_Error(t *testing.T) {
+	server := newVaultTestServer(t)
+
+	r := NewVaultSecretReader(map[string]string{"vault_address": server.URL, "vault_token": "wrong", "vault_path": "app/prod"})
+	if _, err := r.Read(); err == nil || errors.Is(err, ErrInvalidFields) {
+		t.Fatalf("expected an authentication error, got %v", err)
+	}
+}
+
+func TestVaultSecretReader_Diagnostics(t *testing.T) {
+	r := NewConfigMux(
+		WithEnvReader(WithEnviron([]string{"VAULT_ADDRESS=http://127.0.0.1:8200", "VAULT_PATH=app/prod"})),
+		WithVaultReader(),
+	)
+
+	configMap := make(map[string]string)
+	diagnostics, err := Unmarshal(r, &configMap)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if diagnostics["vault_token"] != StatusMissing {
+		t.Fatalf("expected diagnostics[\"vault_token\"] to be %s: %v", StatusMissing, diagnostics)
+	}
+}
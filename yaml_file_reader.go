@@ -34,40 +34,55 @@ func NewYAMLFileReader(path string, opts ...func(*yamlFileReader)) *yamlFileRead
 }
 
 func (r *yamlFileReader) Read() (ReadResult, error) {
-	configMap, diagnostics := make(map[string]string), make(map[string]string)
-	for _, path := range r.paths {
-		info, err := fs.Stat(r.fileSystem, path)
-		if errors.Is(err, fs.ErrNotExist) {
-			diagnostics[path] = "Skipped: Not Found"
-			continue
-		} else if err != nil {
-			return nil, fmt.Errorf("error getting info for path (%s): %v", path, err)
-		}
+	files, diagnostics, err := r.files()
+	if err != nil {
+		return nil, err
+	}
 
-		pathConfig, err := r.getConfigByInfo(info, path)
+	configMap := make(map[string]string)
+	for _, file := range files {
+		fileConfig, err := r.readFile(file)
 		if err != nil {
-			return nil, fmt.Errorf("error reading config from path (%s): %v", path, err)
+			return nil, &ReaderError{Reader: "yaml", Source: file, Err: err}
 		}
 
-		maps.Copy(configMap, pathConfig)
+		maps.Copy(configMap, fileConfig)
 	}
 	return NewDiagnosticReadResult(configMap, diagnostics), nil
 }
 
-func (r *yamlFileReader) getConfigByInfo(info fs.FileInfo, path string) (map[string]string, error) {
-	mode := info.Mode()
-	switch {
-	case mode.IsDir():
-		return r.readDirectory(path)
-	case mode.IsRegular():
-		return r.readFile(path)
-	default:
-		return nil, fmt.Errorf("path %s is a special file (%v) and cannot be read as config", path, mode)
+// files returns the files of the reader's paths, from lowest to highest priority
+// directories are read recursively in lexicographic order. paths that don't exist are reported in the diagnostics
+func (r *yamlFileReader) files() ([]string, map[string]string, error) {
+	var files []string
+	diagnostics := make(map[string]string)
+	for _, path := range r.paths {
+		info, err := fs.Stat(r.fileSystem, path)
+		if errors.Is(err, fs.ErrNotExist) {
+			diagnostics[path] = StatusSkipped + ": Not Found"
+			continue
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("error getting info for path (%s): %w", path, err)
+		}
+
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			dirFiles, err := r.directoryFiles(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			files = append(files, dirFiles...)
+		case mode.IsRegular():
+			files = append(files, path)
+		default:
+			return nil, nil, fmt.Errorf("path %s is a special file (%v) and cannot be read as config", path, mode)
+		}
 	}
+	return files, diagnostics, nil
 }
 
-func (r *yamlFileReader) readDirectory(dir string) (map[string]string, error) {
-	dirConfig := make(map[string]string)
+func (r *yamlFileReader) directoryFiles(dir string) ([]string, error) {
+	var files []string
 
 	err := fs.WalkDir(r.fileSystem, dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -83,29 +98,24 @@ func (r *yamlFileReader) readDirectory(dir string) (map[string]string, error) {
 			return nil
 		}
 
-		fileConfig, err := r.readFile(path)
-		if err != nil {
-			return err
-		}
-
-		maps.Copy(dirConfig, fileConfig)
+		files = append(files, path)
 		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("error walking config directory (%s): %v", dir, err)
+		return nil, fmt.Errorf("error walking config directory (%s): %w", dir, err)
 	}
 
-	return dirConfig, nil
+	return files, nil
 }
 
 func (r *yamlFileReader) readFile(file string) (map[string]string, error) {
 	fileConfig := make(map[string]string)
 	data, err := fs.ReadFile(r.fileSystem, file)
 	if err != nil {
-		return nil, fmt.Errorf("error reading config file(%s): %v", file, err)
+		return nil, fmt.Errorf("error reading config file(%s): %w", file, err)
 	}
 	if err := yaml.Unmarshal(data, &fileConfig); err != nil {
-		return nil, fmt.Errorf("error unmarshalling config file (%s): %v", file, err)
+		return nil, NewFileParseError(file, data, err)
 	}
 	return fileConfig, nil
 }
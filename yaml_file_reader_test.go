@@ -0,0 +1,41 @@
+package conflux
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestYAMLFileReader_ParseError(t *testing.T) {
+	cases := []struct {
+		name            string
+		data            string
+		expectedLine    int
+		expectedColumn  int
+		expectedSnippet string
+	}{
+		{name: "syntax error", data: "ssh_port: 22\nlog_level: [info\n", expectedLine: 2, expectedColumn: 12, expectedSnippet: "log_level: [info"},
+		{name: "value that isn't a string", data: "ssh_port: 22\ndb:\n  host: localhost\n", expectedLine: 3, expectedColumn: 7, expectedSnippet: "  host: localhost"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewConfigMux(WithYAMLFileReader("config/all.yml", WithFileSystem(fstest.MapFS{
+				"config/all.yml": {Data: []byte(tc.data)},
+			})))
+
+			_, err := Unmarshal(r, &map[string]string{})
+
+			var parseErr *FileParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a *FileParseError, got %v", err)
+			}
+			if parseErr.Path != "config/all.yml" || parseErr.Line != tc.expectedLine || parseErr.Column != tc.expectedColumn {
+				t.Errorf("expected error at config/all.yml:%d:%d, got %s:%d:%d", tc.expectedLine, tc.expectedColumn, parseErr.Path, parseErr.Line, parseErr.Column)
+			}
+			if parseErr.Snippet != tc.expectedSnippet {
+				t.Errorf("expected snippet %q, got %q", tc.expectedSnippet, parseErr.Snippet)
+			}
+		})
+	}
+}